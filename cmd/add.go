@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"dev-util/detect"
+	"dev-util/models"
 	"dev-util/storage"
 	"fmt"
 	"os"
@@ -17,18 +19,33 @@ var addCmd = &cobra.Command{
 	Long: `Add a new project to your dev server list. You can specify the project name,
 path to the project directory, and the command to run the dev server.
 
+Give only a name, or --here, to register the current directory with the command
+detected in it; dev add . also picks a name based on the directory.
+
 If no arguments are provided, an interactive mode will be used to collect the information.
+It suggests a name based on the directory and offers the commands it finds there, such
+as package.json scripts, Makefile targets and go run ./cmd/... entries, to pick from.
 
 Examples:
   dev add zensight-fe /path/to/zensight-fe "npm run dev"
   dev add api-server /home/user/api "go run main.go"
   dev add frontend ./frontend "yarn start"
+  dev add web ./web "npm run dev" --task test="npm test" --task lint="npm run lint"
+  dev add frontend ./frontend "npm run dev" --port 3000
+  dev add api ./api "go run ." --tag backend --tag go
+  dev add api                  # Current directory, detected command
+  dev add .                    # Current directory, name from the directory
+  dev add --here --port 3000
   dev add  # Interactive mode`,
 	Args: cobra.RangeArgs(0, 3),
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
+		here, _ := cmd.Flags().GetBool("here")
+		if len(args) == 0 && !here {
 			// Interactive mode
 			runInteractiveAdd()
+		} else if here || len(args) == 1 {
+			// Register the current directory
+			runAddHere(cmd, args)
 		} else if len(args) == 3 {
 			// Non-interactive mode
 			runNonInteractiveAdd(cmd, args)
@@ -39,66 +56,62 @@ Examples:
 	},
 }
 
-func runInteractiveAdd() {
-	var questions = []*survey.Question{
-		{
-			Name: "name",
-			Prompt: &survey.Input{
-				Message: "What is your project named?",
-				Help:    "Enter a unique name for your project",
-			},
-			Validate: survey.Required,
-		},
-		{
-			Name: "path",
-			Prompt: &survey.Input{
-				Message: "What is the path to your project directory?",
-				Help:    "Enter the absolute or relative path to your project directory",
-			},
-			Validate: func(val interface{}) error {
-				if str, ok := val.(string); ok {
-					if strings.TrimSpace(str) == "" {
-						return fmt.Errorf("path cannot be empty")
-					}
-					// Validate path
-					absPath, err := filepath.Abs(str)
-					if err != nil {
-						return fmt.Errorf("invalid path: %v", err)
-					}
-					// Check if directory exists
-					if _, err := os.Stat(absPath); os.IsNotExist(err) {
-						return fmt.Errorf("directory '%s' does not exist", absPath)
-					}
-				}
-				return nil
-			},
-		},
-		{
-			Name: "command",
-			Prompt: &survey.Input{
-				Message: "What command should be used to start the dev server?",
-				Help:    "Enter the command to run your development server (e.g., 'npm run dev', 'go run main.go', 'yarn start')",
-			},
-			Validate: survey.Required,
-		},
-		{
-			Name: "description",
-			Prompt: &survey.Input{
-				Message: "What is the description for this project?",
-				Help:    "Enter an optional description for your project (press Enter to skip)",
-			},
-		},
+// runAddHere registers the current directory. args holds an optional name,
+// where . asks for one based on the directory, and an optional command,
+// which is detected when left out.
+func runAddHere(cmd *cobra.Command, args []string) {
+	if len(args) > 2 {
+		fmt.Println("Error: --here takes the place of the path; provide at most a name and a command")
+		os.Exit(1)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("Error: Failed to get the current directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	name := ""
+	if len(args) > 0 && args[0] != "." {
+		name = args[0]
+	}
+	if name == "" {
+		name = detect.SuggestName(dir, projectExists(getStore()))
+	}
+
+	var command string
+	if len(args) == 2 {
+		command = args[1]
+	} else {
+		candidate, ok := detect.Best(dir)
+		if !ok {
+			fmt.Printf("Error: Could not detect a dev server command in %s; provide one: dev add %s . \"<command>\"\n", dir, name)
+			os.Exit(1)
+		}
+		fmt.Printf("🔍 Detected %s (%s)\n", candidate.Command, candidate.Source)
+		command = candidate.Command
 	}
 
-	answers := struct {
-		Name        string `survey:"name"`
-		Path        string `survey:"path"`
-		Command     string `survey:"command"`
-		Description string `survey:"description"`
-	}{}
+	runNonInteractiveAdd(cmd, []string{name, dir, command})
+}
+
+// customCommandOption is offered after the detected commands to type one in
+const customCommandOption = "Custom command..."
+
+func runInteractiveAdd() {
+	store := getStore()
+
+	var answers struct {
+		Name        string
+		Path        string
+		Command     string
+		Description string
+	}
 
-	// Perform the questions
-	err := survey.Ask(questions, &answers)
+	err := survey.AskOne(&survey.Input{
+		Message: "What is the path to your project directory?",
+		Help:    "Enter the absolute or relative path to your project directory",
+	}, &answers.Path, survey.WithValidator(validateProjectPath))
 	if err != nil {
 		fmt.Printf("Error during interactive setup: %v\n", err)
 		os.Exit(1)
@@ -111,8 +124,38 @@ func runInteractiveAdd() {
 		os.Exit(1)
 	}
 
+	err = survey.AskOne(&survey.Input{
+		Message: "What is your project named?",
+		Help:    "Enter a unique name for your project",
+		Default: detect.SuggestName(absPath, projectExists(store)),
+	}, &answers.Name, survey.WithValidator(survey.Required), survey.WithValidator(validateNewProjectName(store)))
+	if err != nil {
+		fmt.Printf("Error during interactive setup: %v\n", err)
+		os.Exit(1)
+	}
+
+	answers.Command, err = askCommand(absPath)
+	if err != nil {
+		fmt.Printf("Error during interactive setup: %v\n", err)
+		os.Exit(1)
+	}
+
+	err = survey.AskOne(&survey.Input{
+		Message: "What is the description for this project?",
+		Help:    "Enter an optional description for your project (press Enter to skip)",
+	}, &answers.Description)
+	if err != nil {
+		fmt.Printf("Error during interactive setup: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Add the project
-	if err := storage.AddProject(answers.Name, absPath, answers.Command, answers.Description); err != nil {
+	if err := store.Add(models.Project{
+		Name:        answers.Name,
+		Path:        absPath,
+		Command:     answers.Command,
+		Description: answers.Description,
+	}); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -125,42 +168,162 @@ func runInteractiveAdd() {
 	}
 }
 
+// askCommand asks for the dev server command, offering the commands detected
+// in dir to pick from before falling back to typing one in
+func askCommand(dir string) (string, error) {
+	candidates := detect.Detect(dir)
+	if len(candidates) > 0 {
+		options := make([]string, 0, len(candidates)+1)
+		for _, candidate := range candidates {
+			options = append(options, candidate.Command)
+		}
+		options = append(options, customCommandOption)
+
+		var choice int
+		err := survey.AskOne(&survey.Select{
+			Message: "What command should be used to start the dev server?",
+			Help:    "Pick a command found in the project directory, or choose the last option to type your own",
+			Options: options,
+			Description: func(value string, index int) string {
+				if index < len(candidates) {
+					return candidates[index].Source
+				}
+				return ""
+			},
+			PageSize: 12,
+		}, &choice)
+		if err != nil {
+			return "", err
+		}
+		if choice < len(candidates) {
+			return candidates[choice].Command, nil
+		}
+	}
+
+	var command string
+	err := survey.AskOne(&survey.Input{
+		Message: "What command should be used to start the dev server?",
+		Help:    "Enter the command to run your development server (e.g., 'npm run dev', 'go run main.go', 'yarn start')",
+	}, &command, survey.WithValidator(survey.Required))
+	return command, err
+}
+
+// projectExists returns a function reporting whether a project name is taken
+func projectExists(store storage.Store) func(name string) bool {
+	return func(name string) bool {
+		_, err := store.Get(name)
+		return err == nil
+	}
+}
+
+// validateNewProjectName is a survey validator that rejects names already
+// in use or not usable on the dev run command line
+func validateNewProjectName(store storage.Store) survey.Validator {
+	exists := projectExists(store)
+	return func(val interface{}) error {
+		str, ok := val.(string)
+		if !ok {
+			return nil
+		}
+		if err := validateName("project", str); err != nil {
+			return err
+		}
+		if exists(str) {
+			return fmt.Errorf("project '%s' already exists", str)
+		}
+		return nil
+	}
+}
+
+// validateProjectPath is a survey validator that accepts existing directories
+func validateProjectPath(val interface{}) error {
+	if str, ok := val.(string); ok {
+		if strings.TrimSpace(str) == "" {
+			return fmt.Errorf("path cannot be empty")
+		}
+		// Validate path
+		absPath, err := filepath.Abs(str)
+		if err != nil {
+			return fmt.Errorf("invalid path: %v", err)
+		}
+		// Check if directory exists
+		if _, err := os.Stat(absPath); os.IsNotExist(err) {
+			return fmt.Errorf("directory '%s' does not exist", absPath)
+		}
+	}
+	return nil
+}
+
 func runNonInteractiveAdd(cmd *cobra.Command, args []string) {
 	name := args[0]
 	path := args[1]
 	command := args[2]
-	
+
+	if err := validateName("project", name); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Validate path
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		fmt.Printf("Error: Invalid path '%s': %v\n", path, err)
 		os.Exit(1)
 	}
-	
+
 	// Check if directory exists
 	if _, err := os.Stat(absPath); os.IsNotExist(err) {
 		fmt.Printf("Error: Directory '%s' does not exist\n", absPath)
 		os.Exit(1)
 	}
-	
+
 	// Get description from flag if provided
 	description, _ := cmd.Flags().GetString("description")
-	
+
+	project := models.Project{
+		Name:        name,
+		Path:        absPath,
+		Command:     command,
+		Description: description,
+	}
+	applyShellFlag(cmd, &project)
+	applyTaskFlags(cmd, &project)
+	applyRestartFlags(cmd, &project)
+	applyPortFlags(cmd, &project)
+	applyTagFlags(cmd, &project)
+
 	// Add the project
-	if err := storage.AddProject(name, absPath, command, description); err != nil {
+	if err := getStore().Add(project); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
-	
+
 	fmt.Printf("✅ Successfully added project '%s'\n", name)
 	fmt.Printf("   Path: %s\n", absPath)
 	fmt.Printf("   Command: %s\n", command)
 	if description != "" {
 		fmt.Printf("   Description: %s\n", description)
 	}
+	if len(project.Ports) > 0 {
+		fmt.Printf("   Ports: %s\n", formatPorts(project.Ports))
+	}
+	if len(project.Tags) > 0 {
+		fmt.Printf("   Tags: %s\n", strings.Join(project.Tags, ", "))
+	}
+	printTasks(&project)
 }
 
 func init() {
 	rootCmd.AddCommand(addCmd)
 	addCmd.Flags().StringP("description", "d", "", "Description for the project")
+	addCmd.Flags().Bool("here", false, "Register the current directory with the command detected in it")
+	addCmd.Flags().StringArray("task", nil, "Add a named task as name=command (repeatable)")
+	addCmd.Flags().String("shell", "", "How to run commands: none, sh, bash, zsh, or auto to pick sh only when needed (default)")
+	addCmd.RegisterFlagCompletionFunc("shell", completeShells)
+	addCmd.Flags().String("restart", "", "Restart policy when the server exits: never, on-failure or always")
+	addCmd.RegisterFlagCompletionFunc("restart", completeRestartPolicies)
+	addCmd.Flags().IntSlice("port", nil, "TCP port the server listens on, checked before it starts (repeatable)")
+	addCmd.Flags().StringSlice("tag", nil, "Tag the project, e.g. frontend or go (repeatable)")
+	addCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	addCmd.Flags().Int("max-restarts", 0, "Maximum consecutive automatic restarts (0 for the default 5, -1 for no limit)")
 }
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"dev-util/storage"
 	"fmt"
 	"os"
 
@@ -20,34 +19,37 @@ This command is designed to work with shell integration. To set it up:
 
 Then use 'dev-cd <project>' to change directories.
 
+With --group, only projects of that group are accepted and offered for
+completion.
+
 Examples:
   dev-cd zensight-fe
-  dev-cd api-server`,
-	Args: cobra.ExactArgs(1),
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		projects, err := storage.ListProjects()
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-
-		var projectNames []string
-		for _, project := range projects {
-			if toComplete == "" || len(project.Name) >= len(toComplete) && project.Name[:len(toComplete)] == toComplete {
-				projectNames = append(projectNames, project.Name)
-			}
-		}
-
-		return projectNames, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
-	},
+  dev-cd api-server
+  dev cd --group shop shop-api`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProjectNames,
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 
-		project, err := storage.GetProject(name)
+		store := getStore()
+		project, err := store.Get(name)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
+		if groupName, _ := cmd.Flags().GetString("group"); groupName != "" {
+			group, err := store.GetGroup(groupName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if !group.Has(name) {
+				fmt.Fprintf(os.Stderr, "Error: project '%s' is not in group '%s'\n", name, groupName)
+				os.Exit(1)
+			}
+		}
+
 		if _, err := os.Stat(project.Path); os.IsNotExist(err) {
 			fmt.Fprintf(os.Stderr, "Error: Project directory '%s' no longer exists\n", project.Path)
 			os.Exit(1)
@@ -77,5 +79,7 @@ Examples:
 
 func init() {
 	cdCmd.Flags().Bool("path", false, "Output only the project path (for shell integration)")
+	cdCmd.Flags().String("group", "", "Only accept projects in this group")
+	cdCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
 	rootCmd.AddCommand(cdCmd)
 }
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"context"
+	"dev-util/daemon"
+	"dev-util/runner"
+	"dev-util/storage"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run the background daemon that supervises dev servers",
+	Long: `Run the dev daemon in the foreground. The daemon listens on a unix socket
+in the state directory and supervises the dev servers started with
+'dev start', so they keep running after the terminal is closed.
+
+You rarely need to run this yourself: 'dev start' launches the daemon in the
+background when it is not running yet.
+
+Examples:
+  dev daemon        # run in the foreground
+  dev daemon stop   # stop the daemon and every process it supervises`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		stateDir, err := storage.GetStateDir()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		logsDir, err := storage.GetLogsDir()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		log.SetFlags(log.LstdFlags)
+		server := daemon.NewServer(daemon.SocketPath(stateDir), logsDir)
+		if err := server.Serve(ctx); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the daemon and every process it supervises",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := daemonClient()
+		if _, err := client.Send(daemon.Request{Action: daemon.ActionShutdown}); err != nil {
+			if errors.Is(err, daemon.ErrNotRunning) {
+				fmt.Println("The daemon is not running.")
+				return
+			}
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("✅ Daemon stopped")
+	},
+}
+
+var startCmd = &cobra.Command{
+	Use:   "start [name...]",
+	Short: "Start dev servers in the background",
+	Long: `Start one or more projects under the dev daemon. The servers keep running in
+the background after this command returns; use 'dev ps' to see them and
+'dev stop' to stop them. Use project:task to start a specific task.
+
+The daemon is launched automatically if it is not running.
+
+Examples:
+  dev start api-server
+  dev start frontend api-server worker
+  dev start api-server:debug --env PORT=5000`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		specs := daemonSpecs(cmd, args)
+
+		client := daemonClient()
+		if err := client.EnsureRunning("daemon"); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		resp, err := client.Send(daemon.Request{Action: daemon.ActionStart, Specs: specs})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, status := range resp.Processes {
+			fmt.Printf("🚀 Started '%s' in the background (pid %d)\n", status.Name, status.PID)
+		}
+	},
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [name...]",
+	Short: "Stop dev servers running in the background",
+	Long: `Stop dev servers supervised by the daemon. Pass --all to stop every one.
+
+Examples:
+  dev stop api-server
+  dev stop --all`,
+	ValidArgsFunction: completeDaemonNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		if len(args) == 0 && !all {
+			fmt.Println("Error: Please name the processes to stop, or pass --all")
+			os.Exit(1)
+		}
+
+		if _, err := daemonClient().Send(daemon.Request{Action: daemon.ActionStop, Names: args}); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(args) == 0 {
+			fmt.Println("✅ Stopped all background processes")
+			return
+		}
+		for _, name := range args {
+			fmt.Printf("✅ Stopped '%s'\n", name)
+		}
+	},
+}
+
+var restartCmd = &cobra.Command{
+	Use:   "restart [name...]",
+	Short: "Restart dev servers running in the background",
+	Long: `Restart dev servers supervised by the daemon. Registered projects are
+re-read first, so changes made with 'dev edit' or 'dev env' take effect.
+With no names, every supervised process is restarted.
+
+Variables set with --env when a process was started are kept. Pass --env
+again to change them or add more.
+
+Examples:
+  dev restart api-server
+  dev restart api-server --env DEBUG=0
+  dev restart`,
+	ValidArgsFunction: completeDaemonNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		client := daemonClient()
+		extra := envOverrides(cmd)
+
+		// The daemon remembers each process's --env values. Without names,
+		// every process is restarted with its spec rebuilt from the store.
+		restartAll := len(args) == 0
+		stored := map[string]map[string]string{}
+		if resp, err := client.Send(daemon.Request{Action: daemon.ActionStatus}); err == nil {
+			for _, status := range resp.Processes {
+				stored[status.Name] = status.Overrides
+				if restartAll {
+					args = append(args, status.Name)
+				}
+			}
+		}
+
+		// Refresh specs for names that still resolve to projects
+		var specs []daemon.Spec
+		var names []string
+		for _, arg := range args {
+			overrides := map[string]string{}
+			for key, value := range stored[arg] {
+				overrides[key] = value
+			}
+			for key, value := range extra {
+				overrides[key] = value
+			}
+			if spec, err := daemonSpec(arg, overrides); err == nil {
+				specs = append(specs, spec)
+			} else {
+				names = append(names, arg)
+			}
+		}
+
+		if len(specs) > 0 {
+			if err := client.EnsureRunning("daemon"); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		resp, err := client.Send(daemon.Request{Action: daemon.ActionRestart, Names: names, Specs: specs})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, status := range resp.Processes {
+			fmt.Printf("🔄 Restarted '%s' (pid %d)\n", status.Name, status.PID)
+		}
+	},
+}
+
+var psCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "List dev servers running in the background",
+	Long: `List the processes supervised by the dev daemon with their PID, state,
+uptime, exit code and how many times they were restarted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		resp, err := daemonClient().Send(daemon.Request{Action: daemon.ActionStatus})
+		if errors.Is(err, daemon.ErrNotRunning) {
+			fmt.Println("The daemon is not running. Use 'dev start' to run a project in the background.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(resp.Processes) == 0 {
+			fmt.Println("No background processes. Use 'dev start' to run a project in the background.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tPID\tSTATE\tUPTIME\tEXIT\tRESTARTS")
+		fmt.Fprintln(w, "----\t---\t-----\t------\t----\t--------")
+
+		for _, status := range resp.Processes {
+			exit := "-"
+			if status.State != daemon.StateRunning {
+				exit = fmt.Sprintf("%d", status.ExitCode)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\n",
+				status.Name,
+				status.PID,
+				status.State,
+				status.Uptime().Round(time.Second),
+				exit,
+				status.Restarts)
+		}
+
+		w.Flush()
+	},
+}
+
+// daemonClient returns a client for the daemon socket in the state directory
+func daemonClient() *daemon.Client {
+	stateDir, err := storage.GetStateDir()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return daemon.NewClient(daemon.SocketPath(stateDir))
+}
+
+// daemonSpecs resolves dev start arguments into specs, exiting on error
+func daemonSpecs(cmd *cobra.Command, args []string) []daemon.Spec {
+	overrides := envOverrides(cmd)
+
+	var specs []daemon.Spec
+	for _, arg := range args {
+		spec, err := daemonSpec(arg, overrides)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// envOverrides returns the variables given with --env, exiting on error
+func envOverrides(cmd *cobra.Command) map[string]string {
+	overrides := map[string]string{}
+	envFlags, _ := cmd.Flags().GetStringArray("env")
+	for _, value := range envFlags {
+		key, val, err := parseAssignment("env", value)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		overrides[key] = val
+	}
+	return overrides
+}
+
+// daemonSpec resolves a project or project:task argument into a spec with
+// the fully resolved environment
+func daemonSpec(arg string, overrides map[string]string) (daemon.Spec, error) {
+	name, taskName, _ := strings.Cut(arg, ":")
+	project, err := getStore().Get(name)
+	if err != nil {
+		return daemon.Spec{}, err
+	}
+	target, err := newRunTarget(project, taskName)
+	if err != nil {
+		return daemon.Spec{}, err
+	}
+
+	if _, err := os.Stat(project.Path); os.IsNotExist(err) {
+		return daemon.Spec{}, fmt.Errorf("project directory '%s' no longer exists", project.Path)
+	}
+	env, err := runner.BuildEnv(project, overrides)
+	if err != nil {
+		return daemon.Spec{}, fmt.Errorf("failed to load environment for '%s': %v", project.Name, err)
+	}
+
+	return daemon.Spec{Name: target.label(), Project: *project, Task: taskName, Env: env, Overrides: overrides}, nil
+}
+
+// completeDaemonNames completes the names of processes the daemon supervises
+func completeDaemonNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	stateDir, err := storage.GetStateDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	resp, err := daemon.NewClient(daemon.SocketPath(stateDir)).Send(daemon.Request{Action: daemon.ActionStatus})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, status := range resp.Processes {
+		if strings.HasPrefix(status.Name, toComplete) {
+			names = append(names, status.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonStopCmd)
+	rootCmd.AddCommand(daemonCmd)
+
+	startCmd.Flags().StringArrayP("env", "e", nil, "Set an environment variable as KEY=VALUE (repeatable)")
+	rootCmd.AddCommand(startCmd)
+
+	stopCmd.Flags().Bool("all", false, "Stop every background process")
+	rootCmd.AddCommand(stopCmd)
+
+	restartCmd.Flags().StringArrayP("env", "e", nil, "Set an environment variable as KEY=VALUE, in addition to those given to dev start (repeatable)")
+	rootCmd.AddCommand(restartCmd)
+	rootCmd.AddCommand(psCmd)
+}
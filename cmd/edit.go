@@ -0,0 +1,430 @@
+package cmd
+
+import (
+	"dev-util/graph"
+	"dev-util/models"
+	"dev-util/storage"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:     "edit [name]",
+	Aliases: []string{"update"},
+	Short:   "Modify an existing project",
+	Long: `Modify a registered project in place. The project keeps its creation date,
+unlike removing and re-adding it.
+
+Pass flags for the fields you want to change. With no flags, an interactive
+prompt pre-filled with the current values is shown.
+
+Examples:
+  dev edit api-server --command "go run ./cmd/api"
+  dev edit frontend --path ~/code/frontend --description "Customer web app"
+  dev edit old-name --name new-name
+  dev edit web --task storybook="npm run storybook" --remove-task lint
+  dev edit api-server --restart on-failure --max-restarts 10
+  dev edit frontend --port 3000 --remove-port 8080
+  dev edit api-server --ready-http http://localhost:8080/health --ready-timeout 30s
+  dev edit api-server --watch --watch-include "**/*.go" --watch-exclude "*_test.go"
+  dev edit frontend --depends-on api-server,db
+  dev edit legacy-app --tag archived --remove-tag active
+  dev edit api-server  # Interactive mode`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		store := getStore()
+		project, err := store.Get(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		updated := *project
+		if !anyFlagChanged(cmd, "name", "path", "command", "description", "task", "remove-task", "env-file", "shell", "stop-timeout", "restart", "max-restarts",
+			"port", "remove-port", "ready-tcp", "ready-http", "ready-log", "ready-command", "ready-timeout", "ready-interval", "no-ready", "watch", "watch-include", "watch-exclude", "watch-debounce", "watch-gitignore",
+			"depends-on", "remove-depends-on", "tag", "remove-tag") {
+			runInteractiveEdit(&updated)
+		} else {
+			applyEditFlags(cmd, &updated)
+		}
+
+		if strings.TrimSpace(updated.Name) == "" {
+			fmt.Println("Error: Project name cannot be empty")
+			os.Exit(1)
+		}
+		if updated.Name != name {
+			if err := validateName("project", updated.Name); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if strings.TrimSpace(updated.Command) == "" {
+			fmt.Println("Error: Command cannot be empty")
+			os.Exit(1)
+		}
+
+		if err := checkDependencies(store, name, &updated); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := store.Update(name, updated); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if updated.Name != name {
+			renameDependency(store, name, updated.Name)
+		}
+
+		if updated.Name != name {
+			fmt.Printf("✅ Successfully updated project '%s' (renamed from '%s')\n", updated.Name, name)
+		} else {
+			fmt.Printf("✅ Successfully updated project '%s'\n", updated.Name)
+		}
+		fmt.Printf("   Path: %s\n", updated.Path)
+		fmt.Printf("   Command: %s\n", updated.Command)
+		if updated.Description != "" {
+			fmt.Printf("   Description: %s\n", updated.Description)
+		}
+		if len(updated.Tags) > 0 {
+			fmt.Printf("   Tags: %s\n", strings.Join(updated.Tags, ", "))
+		}
+		printTasks(&updated)
+		if len(updated.DependsOn) > 0 {
+			fmt.Printf("   Depends on: %s\n", strings.Join(updated.DependsOn, ", "))
+		}
+	},
+}
+
+// anyFlagChanged reports whether the user set at least one of the named flags
+func anyFlagChanged(cmd *cobra.Command, names ...string) bool {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEditFlags copies every flag the user set onto project
+func applyEditFlags(cmd *cobra.Command, project *models.Project) {
+	if cmd.Flags().Changed("name") {
+		project.Name, _ = cmd.Flags().GetString("name")
+	}
+
+	if cmd.Flags().Changed("path") {
+		path, _ := cmd.Flags().GetString("path")
+		project.Path = resolveProjectPath(path)
+	}
+
+	if cmd.Flags().Changed("command") {
+		project.Command, _ = cmd.Flags().GetString("command")
+	}
+
+	if cmd.Flags().Changed("description") {
+		project.Description, _ = cmd.Flags().GetString("description")
+	}
+
+	applyTaskFlags(cmd, project)
+
+	if cmd.Flags().Changed("env-file") {
+		project.EnvFiles, _ = cmd.Flags().GetStringSlice("env-file")
+	}
+
+	applyShellFlag(cmd, project)
+
+	if cmd.Flags().Changed("stop-timeout") {
+		timeout, _ := cmd.Flags().GetDuration("stop-timeout")
+		project.StopTimeout = models.Duration(timeout)
+	}
+
+	applyRestartFlags(cmd, project)
+	applyPortFlags(cmd, project)
+	applyReadyFlags(cmd, project)
+	applyWatchFlags(cmd, project)
+	applyDependsOnFlags(cmd, project)
+	applyTagFlags(cmd, project)
+}
+
+// applyDependsOnFlags adds and removes the projects this one depends on
+func applyDependsOnFlags(cmd *cobra.Command, project *models.Project) {
+	added, _ := cmd.Flags().GetStringSlice("depends-on")
+	for _, dep := range added {
+		if !containsString(project.DependsOn, dep) {
+			project.DependsOn = append(project.DependsOn, dep)
+		}
+	}
+
+	if !cmd.Flags().Changed("remove-depends-on") {
+		return
+	}
+	removed, _ := cmd.Flags().GetStringSlice("remove-depends-on")
+	var kept []string
+	for _, dep := range project.DependsOn {
+		if !containsString(removed, dep) {
+			kept = append(kept, dep)
+		}
+	}
+	project.DependsOn = kept
+}
+
+// checkDependencies makes sure the dependencies of the edited project, stored
+// under oldName, exist and don't form a cycle
+func checkDependencies(store storage.Store, oldName string, project *models.Project) error {
+	if len(project.DependsOn) == 0 {
+		return nil
+	}
+
+	projects, err := store.List()
+	if err != nil {
+		return err
+	}
+	for i := range projects {
+		if projects[i].Name == oldName {
+			projects[i] = *project
+		}
+	}
+
+	for _, dep := range project.DependsOn {
+		if dep == project.Name {
+			return fmt.Errorf("project '%s' cannot depend on itself", project.Name)
+		}
+	}
+	_, err = graph.New(projects).Order([]string{project.Name})
+	return err
+}
+
+// renameDependency points the depends_on entries of other projects at a
+// renamed project, or drops them when newName is empty because the project
+// was removed
+func renameDependency(store storage.Store, oldName, newName string) {
+	projects, err := store.List()
+	if err != nil {
+		return
+	}
+	for _, project := range projects {
+		if !containsString(project.DependsOn, oldName) {
+			continue
+		}
+
+		var deps []string
+		for _, dep := range project.DependsOn {
+			switch {
+			case dep != oldName:
+				deps = append(deps, dep)
+			case newName != "":
+				deps = append(deps, newName)
+			}
+		}
+		project.DependsOn = deps
+
+		if err := store.Update(project.Name, project); err != nil {
+			fmt.Printf("⚠️  Failed to update the dependencies of '%s': %v\n", project.Name, err)
+		}
+	}
+}
+
+// applyReadyFlags updates the project's readiness check from the --ready-*
+// flags. Choosing a probe type replaces the previous one.
+func applyReadyFlags(cmd *cobra.Command, project *models.Project) {
+	if noReady, _ := cmd.Flags().GetBool("no-ready"); noReady {
+		project.Ready = nil
+		return
+	}
+
+	var probe *models.ReadyCheck
+	switch {
+	case cmd.Flags().Changed("ready-tcp"):
+		probe = &models.ReadyCheck{}
+		probe.TCP, _ = cmd.Flags().GetString("ready-tcp")
+	case cmd.Flags().Changed("ready-http"):
+		probe = &models.ReadyCheck{}
+		probe.HTTP, _ = cmd.Flags().GetString("ready-http")
+	case cmd.Flags().Changed("ready-log"):
+		probe = &models.ReadyCheck{}
+		probe.Log, _ = cmd.Flags().GetString("ready-log")
+		if _, err := regexp.Compile(probe.Log); err != nil {
+			fmt.Printf("Error: invalid --ready-log pattern: %v\n", err)
+			os.Exit(1)
+		}
+	case cmd.Flags().Changed("ready-command"):
+		probe = &models.ReadyCheck{}
+		probe.Command, _ = cmd.Flags().GetString("ready-command")
+	}
+
+	if probe != nil {
+		if project.Ready != nil {
+			probe.Timeout = project.Ready.Timeout
+			probe.Interval = project.Ready.Interval
+		}
+		project.Ready = probe
+	}
+
+	if !anyFlagChanged(cmd, "ready-timeout", "ready-interval") {
+		return
+	}
+	if project.Ready == nil {
+		fmt.Println("Error: set a readiness check with --ready-tcp, --ready-http, --ready-log or --ready-command first")
+		os.Exit(1)
+	}
+	if cmd.Flags().Changed("ready-timeout") {
+		timeout, _ := cmd.Flags().GetDuration("ready-timeout")
+		project.Ready.Timeout = models.Duration(timeout)
+	}
+	if cmd.Flags().Changed("ready-interval") {
+		interval, _ := cmd.Flags().GetDuration("ready-interval")
+		project.Ready.Interval = models.Duration(interval)
+	}
+}
+
+// applyWatchFlags updates the project's watch settings from the --watch*
+// flags
+func applyWatchFlags(cmd *cobra.Command, project *models.Project) {
+	if !anyFlagChanged(cmd, "watch", "watch-include", "watch-exclude", "watch-debounce", "watch-gitignore") {
+		return
+	}
+	if project.Watch == nil {
+		project.Watch = &models.WatchConfig{}
+	}
+
+	if cmd.Flags().Changed("watch") {
+		project.Watch.Enabled, _ = cmd.Flags().GetBool("watch")
+	}
+	if cmd.Flags().Changed("watch-include") {
+		project.Watch.Include, _ = cmd.Flags().GetStringSlice("watch-include")
+	}
+	if cmd.Flags().Changed("watch-exclude") {
+		project.Watch.Exclude, _ = cmd.Flags().GetStringSlice("watch-exclude")
+	}
+	if cmd.Flags().Changed("watch-debounce") {
+		debounce, _ := cmd.Flags().GetDuration("watch-debounce")
+		project.Watch.Debounce = models.Duration(debounce)
+	}
+	if cmd.Flags().Changed("watch-gitignore") {
+		gitignore, _ := cmd.Flags().GetBool("watch-gitignore")
+		project.Watch.Gitignore = &gitignore
+	}
+}
+
+// resolveProjectPath makes path absolute and checks that the directory
+// exists, exiting with an error otherwise
+func resolveProjectPath(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		fmt.Printf("Error: Invalid path '%s': %v\n", path, err)
+		os.Exit(1)
+	}
+
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		fmt.Printf("Error: Directory '%s' does not exist\n", absPath)
+		os.Exit(1)
+	}
+
+	return absPath
+}
+
+func runInteractiveEdit(project *models.Project) {
+	var questions = []*survey.Question{
+		{
+			Name: "name",
+			Prompt: &survey.Input{
+				Message: "Project name:",
+				Default: project.Name,
+				Help:    "Change the name to rename the project",
+			},
+			Validate: survey.Required,
+		},
+		{
+			Name: "path",
+			Prompt: &survey.Input{
+				Message: "Project directory:",
+				Default: project.Path,
+				Help:    "Enter the absolute or relative path to your project directory",
+			},
+			Validate: validateProjectPath,
+		},
+		{
+			Name: "command",
+			Prompt: &survey.Input{
+				Message: "Dev server command:",
+				Default: project.Command,
+				Help:    "Enter the command to run your development server (e.g., 'npm run dev', 'go run main.go', 'yarn start')",
+			},
+			Validate: survey.Required,
+		},
+		{
+			Name: "description",
+			Prompt: &survey.Input{
+				Message: "Description:",
+				Default: project.Description,
+				Help:    "Enter an optional description for your project",
+			},
+		},
+	}
+
+	answers := struct {
+		Name        string `survey:"name"`
+		Path        string `survey:"path"`
+		Command     string `survey:"command"`
+		Description string `survey:"description"`
+	}{}
+
+	if err := survey.Ask(questions, &answers); err != nil {
+		fmt.Printf("Error during interactive edit: %v\n", err)
+		os.Exit(1)
+	}
+
+	project.Name = answers.Name
+	project.Path = resolveProjectPath(answers.Path)
+	project.Command = answers.Command
+	project.Description = answers.Description
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+	editCmd.Flags().String("name", "", "Rename the project")
+	editCmd.Flags().String("path", "", "New path to the project directory")
+	editCmd.Flags().String("command", "", "New command to start the dev server")
+	editCmd.Flags().StringP("description", "d", "", "New description (empty to clear)")
+	editCmd.Flags().StringArray("task", nil, "Add or replace a named task as name=command (repeatable)")
+	editCmd.Flags().StringArray("remove-task", nil, "Remove a named task (repeatable)")
+	editCmd.Flags().String("shell", "", "How to run commands: none, sh, bash, zsh, or auto to pick sh only when needed")
+	editCmd.RegisterFlagCompletionFunc("shell", completeShells)
+	editCmd.Flags().Duration("stop-timeout", 0, "Grace period before a stopping server is killed (0 for the default 10s)")
+	editCmd.Flags().String("restart", "", "Restart policy when the server exits: never, on-failure or always")
+	editCmd.RegisterFlagCompletionFunc("restart", completeRestartPolicies)
+	editCmd.Flags().Int("max-restarts", 0, "Maximum consecutive automatic restarts (0 for the default 5, -1 for no limit)")
+	editCmd.Flags().IntSlice("port", nil, "Add a TCP port the server listens on (repeatable)")
+	editCmd.Flags().IntSlice("remove-port", nil, "Remove a declared port (repeatable)")
+	editCmd.Flags().String("ready-tcp", "", "Readiness check: address or port that accepts connections once ready")
+	editCmd.Flags().String("ready-http", "", "Readiness check: URL that answers GET with a 2xx status once ready")
+	editCmd.Flags().String("ready-log", "", "Readiness check: regular expression matching an output line printed once ready")
+	editCmd.Flags().String("ready-command", "", "Readiness check: command that exits 0 once ready")
+	editCmd.Flags().Duration("ready-timeout", 0, "How long to wait for the readiness check to pass (0 for the default 1m)")
+	editCmd.Flags().Duration("ready-interval", 0, "How long to wait between readiness attempts (0 for the default 500ms)")
+	editCmd.Flags().Bool("no-ready", false, "Remove the readiness check")
+	editCmd.MarkFlagsMutuallyExclusive("ready-tcp", "ready-http", "ready-log", "ready-command", "no-ready")
+	editCmd.Flags().Bool("watch", false, "Restart the server on file changes whenever it is run (--watch=false to turn off)")
+	editCmd.Flags().StringSlice("watch-include", nil, "Glob patterns of files to watch, e.g. \"**/*.go\" (default: all files)")
+	editCmd.Flags().StringSlice("watch-exclude", nil, "Glob patterns of files and directories not to watch")
+	editCmd.Flags().Duration("watch-debounce", 0, "How long to wait for changes to settle before restarting (0 for the default 300ms)")
+	editCmd.Flags().Bool("watch-gitignore", true, "Skip files ignored by .gitignore when watching")
+	editCmd.Flags().StringSlice("depends-on", nil, "Add a project that has to be running before this one starts (repeatable)")
+	editCmd.RegisterFlagCompletionFunc("depends-on", completeProjectNames)
+	editCmd.Flags().StringSlice("remove-depends-on", nil, "Remove a dependency (repeatable)")
+	editCmd.RegisterFlagCompletionFunc("remove-depends-on", completeProjectNames)
+	editCmd.Flags().StringSlice("tag", nil, "Add a tag (repeatable)")
+	editCmd.RegisterFlagCompletionFunc("tag", completeTags)
+	editCmd.Flags().StringSlice("remove-tag", nil, "Remove a tag (repeatable)")
+	editCmd.RegisterFlagCompletionFunc("remove-tag", completeTags)
+	editCmd.Flags().StringSlice("env-file", nil, "Env files to load, relative to the project directory (replaces the list; default .env,.env.local)")
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"dev-util/runner"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage a project's environment variables",
+	Long: `Manage the environment variables passed to a project's commands.
+
+Variables come from, in increasing order of precedence: the environment dev
+runs in, the project's env files (.env and .env.local by default), the
+variables set with 'dev env set', and --env flags on 'dev run'. Values may
+reference other variables as ${VAR}.
+
+Examples:
+  dev env set api-server PORT=4000 DATABASE_URL='postgres://localhost/${USER}'
+  dev env unset api-server PORT
+  dev env list api-server`,
+}
+
+var envSetCmd = &cobra.Command{
+	Use:               "set [name] [KEY=VALUE...]",
+	Short:             "Set environment variables for a project",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeFirstProjectName,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		store := getStore()
+		project, err := store.Get(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if project.Env == nil {
+			project.Env = map[string]string{}
+		}
+		for _, value := range args[1:] {
+			key, val, err := parseAssignment("env", value)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			project.Env[key] = val
+		}
+
+		if err := store.Update(name, *project); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ Updated environment for '%s'\n", name)
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset [name] [KEY...]",
+	Short: "Remove environment variables from a project",
+	Args:  cobra.MinimumNArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completeProjectNames(cmd, args, toComplete)
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		project, err := store.Get(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		var keys []string
+		for key := range project.Env {
+			if strings.HasPrefix(key, toComplete) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		return keys, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		store := getStore()
+		project, err := store.Get(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, key := range args[1:] {
+			if _, ok := project.Env[key]; !ok {
+				fmt.Printf("Error: '%s' is not set for project '%s'\n", key, name)
+				os.Exit(1)
+			}
+			delete(project.Env, key)
+		}
+		if len(project.Env) == 0 {
+			project.Env = nil
+		}
+
+		if err := store.Update(name, *project); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ Updated environment for '%s'\n", name)
+	},
+}
+
+var envListCmd = &cobra.Command{
+	Use:               "list [name]",
+	Short:             "List a project's environment variables",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstProjectName,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		project, err := getStore().Get(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		files := project.EnvFiles
+		if len(files) == 0 {
+			files = runner.DefaultEnvFiles
+		}
+		fmt.Printf("🌱 Environment for '%s'\n", name)
+		fmt.Printf("   Env files: %s\n\n", strings.Join(files, ", "))
+
+		if len(project.Env) == 0 {
+			fmt.Println("No variables set. Use 'dev env set' to add one.")
+			return
+		}
+
+		keys := make([]string, 0, len(project.Env))
+		for key := range project.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE")
+		fmt.Fprintln(w, "---\t-----")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s\t%s\n", key, project.Env[key])
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envSetCmd)
+	envCmd.AddCommand(envUnsetCmd)
+	envCmd.AddCommand(envListCmd)
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"dev-util/models"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// parseAssignment splits a KEY=VALUE style flag value
+func parseAssignment(flag, value string) (string, string, error) {
+	key, val, ok := strings.Cut(value, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid --%s value '%s', expected KEY=VALUE", flag, value)
+	}
+	return key, val, nil
+}
+
+// applyTaskFlags adds or replaces the tasks given with --task name=command
+// and drops those given with --remove-task
+func applyTaskFlags(cmd *cobra.Command, project *models.Project) {
+	if cmd.Flags().Lookup("remove-task") != nil {
+		removed, _ := cmd.Flags().GetStringArray("remove-task")
+		for _, name := range removed {
+			if _, ok := project.Tasks[name]; !ok {
+				fmt.Printf("Error: project '%s' has no task '%s'\n", project.Name, name)
+				os.Exit(1)
+			}
+			delete(project.Tasks, name)
+		}
+	}
+
+	tasks, _ := cmd.Flags().GetStringArray("task")
+	for _, value := range tasks {
+		name, command, err := parseAssignment("task", value)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if project.Tasks == nil {
+			project.Tasks = map[string]models.Task{}
+		}
+		project.Tasks[name] = models.Task{Command: command}
+	}
+
+	if len(project.Tasks) == 0 {
+		project.Tasks = nil
+	}
+}
+
+// printTasks lists a project's named tasks below the usual add/edit summary
+func printTasks(project *models.Project) {
+	if len(project.Tasks) == 0 {
+		return
+	}
+	fmt.Println("   Tasks:")
+	for _, name := range project.TaskNames() {
+		fmt.Printf("     %s: %s\n", name, project.Tasks[name].Command)
+	}
+}
+
+// shellAuto is how --shell names models.ShellAuto, which is stored empty
+const shellAuto = "auto"
+
+// applyShellFlag applies --shell to project, exiting on an unsupported shell
+func applyShellFlag(cmd *cobra.Command, project *models.Project) {
+	if !cmd.Flags().Changed("shell") {
+		return
+	}
+	shell, _ := cmd.Flags().GetString("shell")
+	if shell == shellAuto {
+		shell = models.ShellAuto
+	}
+	if err := models.ValidateShell(shell); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	project.Shell = shell
+}
+
+// completeShells completes the values accepted by --shell
+func completeShells(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return append([]string{shellAuto}, models.Shells...), cobra.ShellCompDirectiveNoFileComp
+}
+
+// applyRestartFlags applies --restart and --max-restarts to project, exiting
+// on an unsupported policy
+func applyRestartFlags(cmd *cobra.Command, project *models.Project) {
+	if cmd.Flags().Changed("restart") {
+		policy, _ := cmd.Flags().GetString("restart")
+		if err := models.ValidateRestart(policy); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if policy == models.RestartNever {
+			policy = ""
+		}
+		project.Restart = policy
+	}
+
+	if cmd.Flags().Changed("max-restarts") {
+		project.MaxRestarts, _ = cmd.Flags().GetInt("max-restarts")
+	}
+}
+
+// completeRestartPolicies completes the values accepted by --restart
+func completeRestartPolicies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return models.RestartPolicies, cobra.ShellCompDirectiveNoFileComp
+}
+
+// applyPortFlags adds the ports given with --port to project and drops those
+// given with --remove-port, exiting on an invalid port number
+func applyPortFlags(cmd *cobra.Command, project *models.Project) {
+	added, _ := cmd.Flags().GetIntSlice("port")
+	for _, port := range added {
+		if err := models.ValidatePort(port); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !containsPort(project.Ports, port) {
+			project.Ports = append(project.Ports, port)
+		}
+	}
+
+	if !cmd.Flags().Changed("remove-port") {
+		return
+	}
+	removed, _ := cmd.Flags().GetIntSlice("remove-port")
+	var kept []int
+	for _, port := range project.Ports {
+		if !containsPort(removed, port) {
+			kept = append(kept, port)
+		}
+	}
+	project.Ports = kept
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTagFlags adds the tags given with --tag and, where the command has
+// it, drops those given with --remove-tag. Tags are kept sorted.
+func applyTagFlags(cmd *cobra.Command, project *models.Project) {
+	added, _ := cmd.Flags().GetStringSlice("tag")
+	for _, tag := range added {
+		tag = strings.TrimSpace(tag)
+		if err := models.ValidateTag(tag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !project.HasTag(tag) {
+			project.Tags = append(project.Tags, tag)
+		}
+	}
+
+	if cmd.Flags().Lookup("remove-tag") != nil && cmd.Flags().Changed("remove-tag") {
+		removed, _ := cmd.Flags().GetStringSlice("remove-tag")
+		var kept []string
+		for _, tag := range project.Tags {
+			if !containsString(removed, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		project.Tags = kept
+	}
+	sort.Strings(project.Tags)
+}
+
+// tagFilter selects projects by tag: a project matches when it has every
+// included tag and none of the excluded ones
+type tagFilter struct {
+	include []string
+	exclude []string
+}
+
+// parseTagFilter reads --tag filter values, where "!tag" excludes a tag
+func parseTagFilter(values []string) (tagFilter, error) {
+	var filter tagFilter
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		tag := strings.TrimPrefix(value, "!")
+		if err := models.ValidateTag(tag); err != nil {
+			return filter, err
+		}
+		if tag != value {
+			filter.exclude = append(filter.exclude, tag)
+		} else {
+			filter.include = append(filter.include, tag)
+		}
+	}
+	return filter, nil
+}
+
+// tagFilterFlag returns the filter given with --tag, exiting when it is
+// invalid
+func tagFilterFlag(cmd *cobra.Command) tagFilter {
+	values, _ := cmd.Flags().GetStringSlice("tag")
+	filter, err := parseTagFilter(values)
+	if err != nil {
+		fail(err)
+	}
+	return filter
+}
+
+func (f tagFilter) empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+func (f tagFilter) match(project *models.Project) bool {
+	for _, tag := range f.include {
+		if !project.HasTag(tag) {
+			return false
+		}
+	}
+	for _, tag := range f.exclude {
+		if project.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns the projects matching the filter, keeping their order
+func (f tagFilter) apply(projects []models.Project) []models.Project {
+	if f.empty() {
+		return projects
+	}
+	filtered := []models.Project{}
+	for i := range projects {
+		if f.match(&projects[i]) {
+			filtered = append(filtered, projects[i])
+		}
+	}
+	return filtered
+}
+
+// String renders the filter for messages, e.g. "go, !legacy"
+func (f tagFilter) String() string {
+	parts := append([]string{}, f.include...)
+	for _, tag := range f.exclude {
+		parts = append(parts, "!"+tag)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// completeTags completes the tags used by registered projects
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := openStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	projects, err := store.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := map[string]bool{}
+	var tags []string
+	for _, project := range projects {
+		for _, tag := range project.Tags {
+			if !seen[tag] && strings.HasPrefix(tag, toComplete) {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTagFilter completes --tag filter values, including the "!tag"
+// form that excludes a tag
+func completeTagFilter(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !strings.HasPrefix(toComplete, "!") {
+		return completeTags(cmd, args, toComplete)
+	}
+	tags, directive := completeTags(cmd, args, strings.TrimPrefix(toComplete, "!"))
+	for i := range tags {
+		tags[i] = "!" + tags[i]
+	}
+	return tags, directive
+}
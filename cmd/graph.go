@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"dev-util/graph"
+	"dev-util/models"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [name...]",
+	Short: "Show the dependencies between projects",
+	Long: `Show which projects depend on which, as declared with
+'dev edit --depends-on'. Without names, every project that has or is a
+dependency is shown, starting from the ones nothing depends on.
+
+Use --dot to print the graph in Graphviz DOT format instead, e.g. to render
+it as an image.
+
+Examples:
+  dev graph
+  dev graph frontend
+  dev graph --dot | dot -Tpng -o deps.png`,
+	ValidArgsFunction: completeProjectNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := getStore()
+		projects, err := store.List()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, name := range args {
+			if _, err := store.Get(name); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		deps := graph.New(projects)
+		roots := args
+		if len(roots) == 0 {
+			roots = dependencyRoots(projects, deps)
+		}
+
+		if dot, _ := cmd.Flags().GetBool("dot"); dot {
+			fmt.Print(deps.DOT(roots))
+			return
+		}
+
+		if len(roots) == 0 {
+			fmt.Println("No dependencies declared. Use 'dev edit <name> --depends-on <other>' to add one.")
+			return
+		}
+		fmt.Print(deps.Tree(roots))
+
+		if err := deps.Check(); err != nil {
+			fmt.Println()
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	},
+}
+
+// dependencyRoots returns the projects nothing depends on that have
+// dependencies themselves, plus one project of a cycle no such project leads
+// to so that it is shown too
+func dependencyRoots(projects []models.Project, deps *graph.Graph) []string {
+	withDeps := map[string]bool{}
+	for _, project := range projects {
+		if len(project.DependsOn) > 0 {
+			withDeps[project.Name] = true
+		}
+	}
+
+	var roots []string
+	for _, name := range deps.Roots() {
+		if withDeps[name] {
+			roots = append(roots, name)
+		}
+	}
+
+	var cycle *graph.CycleError
+	if errors.As(deps.Check(), &cycle) {
+		if _, err := deps.Order(roots); err == nil {
+			roots = append(roots, cycle.Cycle[0])
+		}
+	}
+	return roots
+}
+
+func init() {
+	graphCmd.Flags().Bool("dot", false, "Print the graph in Graphviz DOT format")
+	rootCmd.AddCommand(graphCmd)
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"dev-util/models"
+	"dev-util/storage"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// groupPrefix marks a dev run argument as a group name, e.g. @shop
+const groupPrefix = "@"
+
+var groupCmd = &cobra.Command{
+	Use:   "group",
+	Short: "Manage named groups of projects",
+	Long: `Groups collect projects that belong together, such as the services of
+one product, so they can be listed and started as a unit. A project can
+belong to any number of groups.
+
+Start every project of a group with 'dev run @<group>', or narrow down
+dev list, dev cd and dev run with --group.
+
+Examples:
+  dev group create shop shop-api shop-web shop-worker -d "Online shop"
+  dev group add shop shop-admin
+  dev group remove shop shop-worker
+  dev group list
+  dev run @shop`,
+}
+
+var groupCreateCmd = &cobra.Command{
+	Use:   "create [group] [project...]",
+	Short: "Create a group, optionally with its first projects",
+	Args:  cobra.MinimumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeProjectNames(cmd, args, toComplete)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := validateName("group", name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		store := getStore()
+		if _, err := store.GetGroup(name); err == nil {
+			fmt.Printf("Error: group '%s' already exists\n", name)
+			os.Exit(1)
+		}
+
+		description, _ := cmd.Flags().GetString("description")
+		group := models.Group{Name: name, Description: description, Projects: []string{}}
+		for _, project := range args[1:] {
+			if !group.Has(project) {
+				group.Projects = append(group.Projects, project)
+			}
+		}
+
+		if err := store.SaveGroup(group); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ Successfully created group '%s'\n", name)
+		printGroupMembers(&group)
+	},
+}
+
+var groupAddCmd = &cobra.Command{
+	Use:               "add [group] [project...]",
+	Short:             "Add projects to a group",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeGroupMembers,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := getStore()
+		group, err := store.GetGroup(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, project := range args[1:] {
+			if group.Has(project) {
+				fmt.Printf("⚠️  '%s' is already in group '%s'\n", project, group.Name)
+				continue
+			}
+			group.Projects = append(group.Projects, project)
+		}
+
+		if err := store.SaveGroup(*group); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ Successfully updated group '%s'\n", group.Name)
+		printGroupMembers(group)
+	},
+}
+
+var groupRemoveCmd = &cobra.Command{
+	Use:               "remove [group] [project...]",
+	Short:             "Remove projects from a group",
+	Long:              `Remove projects from a group. The projects themselves stay registered.`,
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeGroupMembers,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := getStore()
+		group, err := store.GetGroup(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		removed := args[1:]
+		for _, project := range removed {
+			if !group.Has(project) {
+				fmt.Printf("⚠️  '%s' is not in group '%s'\n", project, group.Name)
+			}
+		}
+		var kept []string
+		for _, project := range group.Projects {
+			if !containsString(removed, project) {
+				kept = append(kept, project)
+			}
+		}
+		group.Projects = kept
+
+		if err := store.SaveGroup(*group); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ Successfully updated group '%s'\n", group.Name)
+		printGroupMembers(group)
+	},
+}
+
+var groupDeleteCmd = &cobra.Command{
+	Use:               "delete [group]",
+	Short:             "Delete a group, keeping its projects",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGroupNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := getStore().RemoveGroup(args[0]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Successfully deleted group '%s'\n", args[0])
+	},
+}
+
+var groupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all groups and their projects",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, err := getStore().Groups()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		namesOnly, _ := cmd.Flags().GetBool("names-only")
+		if namesOnly {
+			for _, group := range groups {
+				fmt.Println(group.Name)
+			}
+			return
+		}
+
+		if len(groups) == 0 {
+			fmt.Println("No groups defined. Use 'dev group create' to add your first group.")
+			return
+		}
+
+		fmt.Printf("📦 Groups (%d total)\n\n", len(groups))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tPROJECTS\tDESCRIPTION")
+		fmt.Fprintln(w, "----\t--------\t-----------")
+		for _, group := range groups {
+			members := strings.Join(group.Projects, ", ")
+			if members == "" {
+				members = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", group.Name, members, group.Description)
+		}
+		w.Flush()
+	},
+}
+
+// validateName rejects project and group names that could not be told apart
+// on the dev run command line, where @ marks a group and a colon separates a
+// project from its task. kind is "project" or "group".
+func validateName(kind, name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("%s name cannot be empty", kind)
+	case strings.HasPrefix(name, groupPrefix):
+		return fmt.Errorf("%s name '%s' must not start with '%s'; it is only used to refer to groups in dev run", kind, name, groupPrefix)
+	case strings.ContainsAny(name, ": \t"):
+		return fmt.Errorf("%s name '%s' must not contain colons or spaces", kind, name)
+	}
+	return nil
+}
+
+func printGroupMembers(group *models.Group) {
+	if len(group.Projects) == 0 {
+		fmt.Println("   Projects: none")
+		return
+	}
+	fmt.Printf("   Projects: %s\n", strings.Join(group.Projects, ", "))
+}
+
+// groupFilter returns the group selected with --group, or nil when the flag
+// was not given. It exits when the group does not exist.
+func groupFilter(cmd *cobra.Command, store storage.Store) *models.Group {
+	name, _ := cmd.Flags().GetString("group")
+	if name == "" {
+		return nil
+	}
+	group, err := store.GetGroup(name)
+	if err != nil {
+		fail(err)
+	}
+	return group
+}
+
+// filterGroup returns the projects that are members of group, keeping their
+// order. A nil group keeps every project.
+func filterGroup(projects []models.Project, group *models.Group) []models.Project {
+	if group == nil {
+		return projects
+	}
+	filtered := []models.Project{}
+	for _, project := range projects {
+		if group.Has(project.Name) {
+			filtered = append(filtered, project)
+		}
+	}
+	return filtered
+}
+
+// completeGroupNames is a ValidArgsFunction that completes group names
+func completeGroupNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := openStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	groups, err := store.Groups()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, group := range groups {
+		if strings.HasPrefix(group.Name, toComplete) {
+			names = append(names, group.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGroupMembers completes a group name as the first argument and
+// project names after it
+func completeGroupMembers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeGroupNames(cmd, args, toComplete)
+	}
+	return completeProjectNames(cmd, args, toComplete)
+}
+
+func init() {
+	groupCreateCmd.Flags().StringP("description", "d", "", "Description for the group")
+	groupListCmd.Flags().Bool("names-only", false, "Output only group names (for shell completions)")
+
+	groupCmd.AddCommand(groupCreateCmd)
+	groupCmd.AddCommand(groupAddCmd)
+	groupCmd.AddCommand(groupRemoveCmd)
+	groupCmd.AddCommand(groupDeleteCmd)
+	groupCmd.AddCommand(groupListCmd)
+	rootCmd.AddCommand(groupCmd)
+}
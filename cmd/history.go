@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"dev-util/storage"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [name]",
+	Short: "Show the run history of your projects",
+	Long: `Show previous 'dev run' invocations with their start time, duration and
+exit code, newest first. Run history is recorded by the sqlite backend.
+
+Examples:
+  dev history
+  dev history api-server --limit 5`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		history, err := storage.History(getStore())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		project := ""
+		if len(args) == 1 {
+			project = args[0]
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		runs, err := history.Runs(project, limit)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(runs) == 0 {
+			fmt.Println("No runs recorded yet.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROJECT\tSTARTED\tDURATION\tEXIT")
+		fmt.Fprintln(w, "-------\t-------\t--------\t----")
+
+		for _, run := range runs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n",
+				run.Project,
+				run.StartedAt.Local().Format("2006-01-02 15:04:05"),
+				run.Duration.Round(time.Second),
+				run.ExitCode)
+		}
+
+		w.Flush()
+	},
+}
+
+func init() {
+	historyCmd.Flags().IntP("limit", "n", 20, "Maximum number of runs to show (0 for all)")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"dev-util/storage"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import projects and groups from a projects.json file",
+	Long: `Import projects from a projects.json file into the configured storage
+backend. This is how existing projects are moved over after switching to the
+sqlite backend. Projects and groups whose name is already registered are skipped.
+
+With no file, the projects.json in the config directory is imported.
+
+Examples:
+  DEV_UTIL_BACKEND=sqlite dev import
+  dev import ~/backup/projects.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var path string
+		if len(args) == 1 {
+			absPath, err := filepath.Abs(args[0])
+			if err != nil {
+				fmt.Printf("Error: Invalid path '%s': %v\n", args[0], err)
+				os.Exit(1)
+			}
+			path = absPath
+		} else {
+			configPath, err := storage.GetConfigPath()
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			path = configPath
+		}
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			fmt.Printf("Error: File '%s' does not exist\n", path)
+			os.Exit(1)
+		}
+
+		store := getStore()
+		if jsonStore, ok := store.(*storage.JSONStore); ok && jsonStore.Path() == path {
+			fmt.Println("Error: Cannot import a file into itself; select another backend first (e.g. DEV_UTIL_BACKEND=sqlite)")
+			os.Exit(1)
+		}
+
+		source, err := storage.NewJSONStore(path).Load()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		imported, skipped, err := storage.ImportProjects(store, source.Projects)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✅ Imported %d project(s) from %s\n", imported, path)
+		if skipped > 0 {
+			fmt.Printf("   Skipped %d project(s) that already exist\n", skipped)
+		}
+
+		if len(source.Groups) > 0 {
+			imported, skipped, err := storage.ImportGroups(store, source.Groups)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✅ Imported %d group(s)\n", imported)
+			if skipped > 0 {
+				fmt.Printf("   Skipped %d group(s) that already exist\n", skipped)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
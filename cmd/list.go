@@ -1,9 +1,9 @@
 package cmd
 
 import (
-	"dev-util/storage"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -13,13 +13,30 @@ var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all registered projects",
 	Long: `List all registered projects with their details including name, path,
-command, and creation date.`,
+command, number of named tasks, tags and creation date.
+
+Use --group to list only the projects of one group, and --tag to list only
+projects with a tag. Prefix a tag with ! to list projects without it; give
+--tag several times to combine conditions.
+
+Use --output json, yaml or csv, or --template, for output meant for scripts.
+
+Examples:
+  dev list --output json
+  dev list --template '{{.Name}}\t{{.Path}}'
+  dev list --tag go
+  dev list --tag go --tag '!legacy'
+  dev list --group shop`,
+	Annotations: map[string]string{structuredOutputAnnotation: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
-		projects, err := storage.ListProjects()
+		store := getStore()
+		projects, err := store.List()
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			fail(err)
 		}
+		group := groupFilter(cmd, store)
+		tags := tagFilterFlag(cmd)
+		projects = tags.apply(filterGroup(projects, group))
 
 		namesOnly, _ := cmd.Flags().GetBool("names-only")
 		if namesOnly {
@@ -29,23 +46,54 @@ command, and creation date.`,
 			return
 		}
 
-		if len(projects) == 0 {
-			fmt.Println("No projects registered. Use 'dev add' to add your first project.")
+		if outputFormat() != outputTable {
+			groups, err := store.Groups()
+			if err != nil {
+				fail(err)
+			}
+			out := make([]projectOutput, len(projects))
+			for i := range projects {
+				out[i] = newProjectOutput(&projects[i], groups)
+			}
+			writeProjects(out)
+			return
+		}
+
+		if !tags.empty() && len(projects) == 0 {
+			fmt.Printf("No projects match tags %s.\n", tags)
 			return
 		}
 
-		fmt.Printf("📋 Registered Projects (%d total)\n\n", len(projects))
+		if group != nil {
+			if len(projects) == 0 {
+				fmt.Printf("Group '%s' has no projects. Use 'dev group add %s <project>' to add one.\n", group.Name, group.Name)
+				return
+			}
+			fmt.Printf("📋 Projects in group '%s' (%d total)\n\n", group.Name, len(projects))
+		} else {
+			if len(projects) == 0 {
+				fmt.Println("No projects registered. Use 'dev add' to add your first project.")
+				return
+			}
+			fmt.Printf("📋 Registered Projects (%d total)\n\n", len(projects))
+		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "NAME\tPATH\tCOMMAND\tCREATED")
-		fmt.Fprintln(w, "----\t----\t-------\t-------")
+		fmt.Fprintln(w, "NAME\tPATH\tCOMMAND\tTASKS\tTAGS\tCREATED")
+		fmt.Fprintln(w, "----\t----\t-------\t-----\t----\t-------")
 
 		for _, project := range projects {
 			created := project.CreatedAt.Format("2006-01-02")
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			tags := strings.Join(project.Tags, ",")
+			if tags == "" {
+				tags = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
 				project.Name,
 				project.Path,
 				project.Command,
+				len(project.Tasks),
+				tags,
 				created)
 		}
 
@@ -55,5 +103,9 @@ command, and creation date.`,
 
 func init() {
 	listCmd.Flags().Bool("names-only", false, "Output only project names (for shell completions)")
+	listCmd.Flags().StringSlice("tag", nil, "Only list projects with this tag, or without it as !tag (repeatable)")
+	listCmd.RegisterFlagCompletionFunc("tag", completeTagFilter)
+	listCmd.Flags().String("group", "", "Only list the projects in this group")
+	listCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
 	rootCmd.AddCommand(listCmd)
 }
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"context"
+	"dev-util/logs"
+	"dev-util/runner"
+	"dev-util/storage"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// followInterval is how often 'dev logs -f' checks for new output
+const followInterval = 250 * time.Millisecond
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [name...]",
+	Short: "Show the captured output of dev servers",
+	Long: `Show the output captured from 'dev run' and 'dev start'. Logs are kept per
+project in the state directory and rotated when they grow large.
+
+Give several names to interleave their logs by time, each line prefixed with
+the project name.
+
+Examples:
+  dev logs api-server
+  dev logs api-server -f
+  dev logs api-server --since 10m --grep error
+  dev logs frontend api-server --tail 50`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		logsDir, err := storage.GetLogsDir()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		filter, err := logFilterFromFlags(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		tail, _ := cmd.Flags().GetInt("tail")
+		follow, _ := cmd.Flags().GetBool("follow")
+		timestamps, _ := cmd.Flags().GetBool("timestamps")
+
+		printer := newLogPrinter(args, timestamps)
+
+		// Start following before reading so no line falls in between
+		var followers []*logs.Follower
+		if follow {
+			for _, name := range args {
+				followers = append(followers, logs.Follow(logsDir, name, filter))
+			}
+		}
+
+		var lists [][]logs.Entry
+		for _, name := range args {
+			entries, err := logs.Read(logsDir, name, filter)
+			if err != nil && !follow {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			lists = append(lists, entries)
+		}
+		for _, entry := range logs.Tail(logs.Merge(lists...), tail) {
+			printer.print(entry)
+		}
+
+		if !follow {
+			return
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ticker := time.NewTicker(followInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			lists = lists[:0]
+			for _, follower := range followers {
+				entries, err := follower.Poll()
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				lists = append(lists, entries)
+			}
+			for _, entry := range logs.Merge(lists...) {
+				printer.print(entry)
+			}
+		}
+	},
+}
+
+// logFilterFromFlags builds the filter for --since and --grep
+func logFilterFromFlags(cmd *cobra.Command) (logs.Filter, error) {
+	var filter logs.Filter
+
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		t, err := parseSince(since)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = t
+	}
+
+	if pattern, _ := cmd.Flags().GetString("grep"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --grep pattern: %v", err)
+		}
+		filter.Grep = re
+	}
+
+	return filter, nil
+}
+
+// parseSince accepts a duration such as "10m" or an absolute time
+func parseSince(value string) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value '%s', expected a duration like 10m or a time like 2006-01-02 15:04", value)
+}
+
+// logPrinter writes log entries, prefixing them with the project name when
+// several logs are shown together
+type logPrinter struct {
+	prefixes   map[string]string
+	timestamps bool
+}
+
+func newLogPrinter(names []string, timestamps bool) *logPrinter {
+	p := &logPrinter{timestamps: timestamps}
+	if len(names) < 2 {
+		return p
+	}
+
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	color := runner.UseColor(os.Stdout)
+	p.prefixes = map[string]string{}
+	for i, name := range names {
+		p.prefixes[name] = runner.FormatPrefix(name, i, width, color)
+	}
+	return p
+}
+
+func (p *logPrinter) print(entry logs.Entry) {
+	line := p.prefixes[entry.Name]
+	if p.timestamps {
+		line += entry.Time.Local().Format("2006-01-02 15:04:05.000") + " "
+	}
+	fmt.Println(line + entry.Text)
+}
+
+func init() {
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new output as it is written")
+	logsCmd.Flags().String("since", "", "Only show output newer than a duration (10m, 2h) or time")
+	logsCmd.Flags().IntP("tail", "n", -1, "Only show the last N lines (-1 for all)")
+	logsCmd.Flags().String("grep", "", "Only show lines matching a regular expression")
+	logsCmd.Flags().BoolP("timestamps", "t", false, "Show the time each line was written")
+	rootCmd.AddCommand(logsCmd)
+}
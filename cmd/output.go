@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"dev-util/models"
+	"dev-util/runner"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats selected with --output
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputYAML     = "yaml"
+	outputCSV      = "csv"
+	outputTemplate = "template"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV}
+
+// structuredOutputAnnotation marks commands that honour --output and
+// --template; every other command rejects formats other than table
+const structuredOutputAnnotation = "structured-output"
+
+var (
+	outputFlag   string
+	templateFlag string
+)
+
+// outputFormat returns the format selected on the command line
+func outputFormat() string {
+	if templateFlag != "" {
+		return outputTemplate
+	}
+	if outputFlag == "" {
+		return outputTable
+	}
+	return strings.ToLower(outputFlag)
+}
+
+// validateOutput checks --output and --template against each other and
+// against what cmd supports
+func validateOutput(cmd *cobra.Command) error {
+	format := strings.ToLower(outputFlag)
+	if format != "" && !containsString(outputFormats, format) {
+		return fmt.Errorf("unknown output format '%s' (supported: %s)", outputFlag, strings.Join(outputFormats, ", "))
+	}
+	if templateFlag != "" && format != "" && format != outputTable {
+		return fmt.Errorf("--template cannot be combined with --output %s", format)
+	}
+	if outputFormat() != outputTable && cmd.Annotations[structuredOutputAnnotation] == "" {
+		return fmt.Errorf("'%s' does not support --output or --template", cmd.CommandPath())
+	}
+	return nil
+}
+
+// fail reports err and exits. With --output json or yaml the error is
+// written to stdout as {"error": "..."} so scripts can parse it.
+func fail(err error) {
+	switch outputFormat() {
+	case outputJSON, outputYAML:
+		writeStructured(map[string]string{"error": err.Error()})
+	default:
+		fmt.Printf("Error: %v\n", err)
+	}
+	os.Exit(1)
+}
+
+// writeStructured prints v as JSON or YAML, whichever was selected
+func writeStructured(v interface{}) {
+	if outputFormat() == outputYAML {
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		enc.Close()
+		return
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+// writeTemplate executes the --template once per item, each followed by a
+// newline unless the template ends with one
+func writeTemplate(items ...interface{}) {
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"join": strings.Join,
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(templateFlag)
+	if err != nil {
+		fail(fmt.Errorf("invalid template: %v", err))
+	}
+
+	for _, item := range items {
+		if err := tmpl.Execute(os.Stdout, item); err != nil {
+			fail(fmt.Errorf("template failed: %v", err))
+		}
+		if !strings.HasSuffix(templateFlag, "\n") {
+			fmt.Println()
+		}
+	}
+}
+
+// projectOutput is the machine-readable form of a project used by --output
+// json, yaml and csv and passed to --template. Its field names are a stable
+// interface: fields may be added, but are never renamed or removed. Empty
+// values are written out rather than omitted.
+type projectOutput struct {
+	Name        string                `json:"name" yaml:"name"`
+	Path        string                `json:"path" yaml:"path"`
+	Command     string                `json:"command" yaml:"command"`
+	Description string                `json:"description" yaml:"description"`
+	Tasks       map[string]taskOutput `json:"tasks" yaml:"tasks"`
+	Env         map[string]string     `json:"env" yaml:"env"`
+	EnvFiles    []string              `json:"env_files" yaml:"env_files"`
+	Shell       string                `json:"shell" yaml:"shell"`
+	Restart     string                `json:"restart" yaml:"restart"`
+	Watch       bool                  `json:"watch" yaml:"watch"`
+	Ready       string                `json:"ready" yaml:"ready"`
+	Tags        []string              `json:"tags" yaml:"tags"`
+	Groups      []string              `json:"groups" yaml:"groups"`
+	Ports       []int                 `json:"ports" yaml:"ports"`
+	DependsOn   []string              `json:"depends_on" yaml:"depends_on"`
+	CreatedAt   time.Time             `json:"created_at" yaml:"created_at"`
+	UpdatedAt   *time.Time            `json:"updated_at" yaml:"updated_at"`
+}
+
+type taskOutput struct {
+	Command     string `json:"command" yaml:"command"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// newProjectOutput converts a project, listing the groups it belongs to
+func newProjectOutput(project *models.Project, groups []models.Group) projectOutput {
+	out := projectOutput{
+		Name:        project.Name,
+		Path:        project.Path,
+		Command:     project.Command,
+		Description: project.Description,
+		Tasks:       map[string]taskOutput{},
+		Env:         map[string]string{},
+		EnvFiles:    append([]string{}, project.EnvFiles...),
+		Shell:       project.Shell,
+		Restart:     project.Restart,
+		Watch:       project.Watch != nil && project.Watch.Enabled,
+		Tags:        append([]string{}, project.Tags...),
+		Groups:      []string{},
+		Ports:       append([]int{}, project.Ports...),
+		DependsOn:   append([]string{}, project.DependsOn...),
+		CreatedAt:   project.CreatedAt,
+	}
+	for name, task := range project.Tasks {
+		out.Tasks[name] = taskOutput{Command: task.Command, Description: task.Description}
+	}
+	for key, value := range project.Env {
+		out.Env[key] = value
+	}
+	if len(out.EnvFiles) == 0 {
+		out.EnvFiles = append(out.EnvFiles, runner.DefaultEnvFiles...)
+	}
+	if out.Shell == models.ShellAuto {
+		out.Shell = shellAuto
+	}
+	if out.Restart == "" {
+		out.Restart = models.RestartNever
+	}
+	if project.Ready != nil {
+		out.Ready = project.Ready.Kind() + " " + project.Ready.Target()
+	}
+	for _, group := range groups {
+		if group.Has(project.Name) {
+			out.Groups = append(out.Groups, group.Name)
+		}
+	}
+	if !project.UpdatedAt.IsZero() {
+		updated := project.UpdatedAt
+		out.UpdatedAt = &updated
+	}
+	return out
+}
+
+// projectCSVHeader names the columns written by projectCSVRow
+var projectCSVHeader = []string{"name", "path", "command", "description", "tasks", "tags", "groups", "ports", "depends_on", "created_at", "updated_at"}
+
+// projectCSVRow flattens a project for CSV output. Lists are joined with
+// semicolons and tasks are given by name.
+func projectCSVRow(out projectOutput) []string {
+	tasks := make([]string, 0, len(out.Tasks))
+	for name := range out.Tasks {
+		tasks = append(tasks, name)
+	}
+	sort.Strings(tasks)
+
+	ports := make([]string, len(out.Ports))
+	for i, port := range out.Ports {
+		ports[i] = strconv.Itoa(port)
+	}
+
+	updated := ""
+	if out.UpdatedAt != nil {
+		updated = out.UpdatedAt.Format(time.RFC3339)
+	}
+
+	return []string{
+		out.Name,
+		out.Path,
+		out.Command,
+		out.Description,
+		strings.Join(tasks, ";"),
+		strings.Join(out.Tags, ";"),
+		strings.Join(out.Groups, ";"),
+		strings.Join(ports, ";"),
+		strings.Join(out.DependsOn, ";"),
+		out.CreatedAt.Format(time.RFC3339),
+		updated,
+	}
+}
+
+// writeProjects prints projects in the selected machine-readable format
+func writeProjects(projects []projectOutput) {
+	switch outputFormat() {
+	case outputJSON, outputYAML:
+		writeStructured(struct {
+			Projects []projectOutput `json:"projects" yaml:"projects"`
+		}{projects})
+	case outputCSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(projectCSVHeader)
+		for _, project := range projects {
+			w.Write(projectCSVRow(project))
+		}
+		w.Flush()
+	case outputTemplate:
+		items := make([]interface{}, len(projects))
+		for i := range projects {
+			items[i] = projects[i]
+		}
+		writeTemplate(items...)
+	}
+}
+
+func completeOutputFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return outputFormats, cobra.ShellCompDirectiveNoFileComp
+}
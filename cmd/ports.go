@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"dev-util/models"
+	"dev-util/ports"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// portKillTimeout is how long a process holding a port gets to exit after
+// the user chose to kill it
+const portKillTimeout = 5 * time.Second
+
+var portsCmd = &cobra.Command{
+	Use:   "ports",
+	Short: "List the ports declared by projects and whether they are in use",
+	Long: `List every port declared by a registered project and check whether it
+is currently in use. On Linux the process listening on a port is shown when
+it can be determined.
+
+Declare ports with 'dev add --port' or 'dev edit --port'. dev run checks a
+project's ports before starting its dev server.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		projects, err := getStore().List()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		type declared struct {
+			port    int
+			project string
+		}
+		var entries []declared
+		for _, project := range projects {
+			for _, port := range project.Ports {
+				entries = append(entries, declared{port: port, project: project.Name})
+			}
+		}
+		if len(entries) == 0 {
+			fmt.Println("No ports declared. Use 'dev edit <name> --port <port>' to declare one.")
+			return
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].port != entries[j].port {
+				return entries[i].port < entries[j].port
+			}
+			return entries[i].project < entries[j].project
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PORT\tPROJECT\tSTATUS\tPID\tPROCESS")
+		fmt.Fprintln(w, "----\t-------\t------\t---\t-------")
+
+		for _, entry := range entries {
+			status := ports.Check(entry.port)
+			state, pid, process := "free", "-", "-"
+			if status.InUse {
+				state = "in use"
+				if status.Owner != nil {
+					pid = fmt.Sprintf("%d", status.Owner.PID)
+					process = status.Owner.Name
+				}
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", entry.port, entry.project, state, pid, process)
+		}
+
+		w.Flush()
+	},
+}
+
+// checkPorts makes sure the ports declared by a project are free before its
+// dev server starts. When a port is taken, the user is offered to kill the
+// process holding it; otherwise dev exits.
+func checkPorts(project *models.Project) {
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+
+	for _, port := range project.Ports {
+		status := ports.Check(port)
+		if !status.InUse {
+			continue
+		}
+
+		if status.Owner == nil {
+			fmt.Printf("Error: port %d needed by '%s' is already in use by another process\n", port, project.Name)
+			os.Exit(1)
+		}
+
+		fmt.Printf("⚠️  Port %d needed by '%s' is already in use by %s\n", port, project.Name, status.Owner)
+		if status.Owner.Command != "" {
+			fmt.Printf("   Command: %s\n", status.Owner.Command)
+		}
+		if !interactive {
+			fmt.Printf("Error: port %d is already in use\n", port)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Kill %s and continue? (y/N): ", status.Owner)
+		var response string
+		fmt.Scanln(&response)
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Operation cancelled.")
+			os.Exit(1)
+		}
+
+		if err := ports.Kill(status.Owner, port, portKillTimeout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Stopped %s\n\n", status.Owner)
+	}
+}
+
+// formatPorts lists ports for output, e.g. "3000, 8080"
+func formatPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, port := range ports {
+		parts[i] = fmt.Sprintf("%d", port)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(portsCmd)
+}
@@ -1,9 +1,9 @@
 package cmd
 
 import (
-	"dev-util/storage"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -14,33 +14,35 @@ var removeCmd = &cobra.Command{
 	Long: `Remove a project from your registered projects. This will permanently
 delete the project configuration.
 
+Instead of a name, give --tag to remove every project with a tag at once.
+Prefix a tag with ! to select projects without it.
+
 Examples:
   dev remove zensight-fe
-  dev remove api-server`,
-	Args: cobra.ExactArgs(1),
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		// Get list of registered projects for completion
-		projects, err := storage.ListProjects()
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
+  dev remove api-server
+  dev remove --tag archived`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		tags := tagFilterFlag(cmd)
+		force, _ := cmd.Flags().GetBool("force")
+
+		if len(args) == 1 && !tags.empty() {
+			fmt.Println("Error: Give either a project name or --tag, not both")
+			os.Exit(1)
 		}
-		
-		var projectNames []string
-		for _, project := range projects {
-			// Only show projects that match the current input
-			if toComplete == "" || len(project.Name) >= len(toComplete) && project.Name[:len(toComplete)] == toComplete {
-				projectNames = append(projectNames, project.Name)
+		if len(args) == 0 {
+			if tags.empty() {
+				fmt.Println("Error: Specify a project to remove, or select projects with --tag")
+				os.Exit(1)
 			}
+			removeTagged(tags, force)
+			return
 		}
-		
-		// Return with NoSpace directive to prevent adding space after completion
-		return projectNames, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
-	},
-	Run: func(cmd *cobra.Command, args []string) {
+
 		name := args[0]
-		
+
 		// Confirm removal
-		force, _ := cmd.Flags().GetBool("force")
 		if !force {
 			fmt.Printf("Are you sure you want to remove project '%s'? (y/N): ", name)
 			var response string
@@ -50,18 +52,69 @@ Examples:
 				return
 			}
 		}
-		
+
 		// Remove the project
-		if err := storage.RemoveProject(name); err != nil {
+		if err := getStore().Remove(name); err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		
+
+		renameDependency(getStore(), name, "")
+
 		fmt.Printf("✅ Successfully removed project '%s'\n", name)
 	},
 }
 
+// removeTagged removes every project matching tags after one confirmation
+// for all of them
+func removeTagged(tags tagFilter, force bool) {
+	store := getStore()
+	projects, err := store.List()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	projects = tags.apply(projects)
+	if len(projects) == 0 {
+		fmt.Printf("No projects match tags %s.\n", tags)
+		return
+	}
+
+	names := make([]string, len(projects))
+	for i, project := range projects {
+		names[i] = project.Name
+	}
+
+	if !force {
+		fmt.Printf("This will remove %d project(s): %s\n", len(names), strings.Join(names, ", "))
+		fmt.Print("Are you sure? (y/N): ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" && response != "yes" {
+			fmt.Println("Operation cancelled.")
+			return
+		}
+	}
+
+	failed := false
+	for _, name := range names {
+		if err := store.Remove(name); err != nil {
+			fmt.Printf("❌ Failed to remove '%s': %v\n", name, err)
+			failed = true
+			continue
+		}
+		renameDependency(store, name, "")
+		fmt.Printf("✅ Successfully removed project '%s'\n", name)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(removeCmd)
 	removeCmd.Flags().BoolP("force", "f", false, "Remove without confirmation")
+	removeCmd.Flags().StringSlice("tag", nil, "Remove every project with this tag, or without it as !tag (repeatable)")
+	removeCmd.RegisterFlagCompletionFunc("tag", completeTagFilter)
 }
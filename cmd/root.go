@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"dev-util/storage"
 	"fmt"
 	"os"
 
@@ -13,11 +14,34 @@ var rootCmd = &cobra.Command{
 	Long: `Dev is a CLI tool that helps you manage and start development servers 
 for your projects from anywhere. You can register projects and start their 
 dev servers with simple commands.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if configPath, _ := cmd.Flags().GetString("config"); configPath != "" {
+			storage.SetConfigPath(configPath)
+		}
+		if err := validateOutput(cmd); err != nil {
+			fail(err)
+		}
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	err := rootCmd.Execute()
+	closeStore()
+	if err != nil {
+		// Usage errors are also reported on stdout in a parseable form
+		switch outputFormat() {
+		case outputJSON, outputYAML:
+			writeStructured(map[string]string{"error": err.Error()})
+		default:
+			fmt.Fprintln(os.Stderr, err)
+		}
 		os.Exit(1)
 	}
 }
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "", "Output format for list and show: table, json, yaml or csv (default table)")
+	rootCmd.RegisterFlagCompletionFunc("output", completeOutputFormats)
+	rootCmd.PersistentFlags().StringVar(&templateFlag, "template", "", "Format list and show output with a Go template, e.g. '{{.Name}} {{.Path}}'")
+	rootCmd.PersistentFlags().String("config", "", "Path to an alternate projects file (overrides $"+storage.ConfigEnv+")")
+}
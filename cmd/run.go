@@ -1,112 +1,787 @@
 package cmd
 
 import (
+	"context"
+	"dev-util/graph"
+	"dev-util/logs"
+	"dev-util/models"
+	"dev-util/ports"
+	"dev-util/runner"
 	"dev-util/storage"
+	"dev-util/watch"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"runtime"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var runCmd = &cobra.Command{
-	Use:   "run [name]",
+	Use:   "run [name] [task]",
 	Short: "Start the dev server for a project",
 	Long: `Start the development server for a registered project. The command will
 change to the project directory and execute the configured command.
 
+Give a task name to run one of the project's named tasks instead of the
+default command. Without a name, the project whose directory you are in,
+or in a subdirectory of, is run.
+
+The command runs in its own process group. Ctrl-C, SIGTERM and SIGHUP are
+passed on to the whole group, so child processes spawned by npm or a shell
+are stopped too, and anything still running after the grace period is
+killed. dev exits with the command's exit code.
+
+Before a project's dev server starts, the ports it declares are checked.
+When one is already taken, the process holding it is shown and you can
+choose to kill it or abort.
+
+With --watch (or watch.enabled in the project's configuration) the command
+is restarted whenever files in the project directory change. The project's
+watch settings select the files with include and exclude globs; files
+ignored by git are skipped unless watch.gitignore is false.
+
+Give several project names to start them all at once. Their output is
+interleaved with each line prefixed by the project name, and Ctrl-C stops
+every one of them. Use project:task to run a specific task of a project,
+and @group to run every project of a group. --group and --tag narrow the
+given projects down, or select the projects to run when none are given.
+With two arguments, the second is treated as a task when the first project
+defines a task of that name.
+
+Projects listed in depends_on are started first, in dependency order, and
+each one waits until its dependencies pass their readiness checks.
+Dependencies that are already running are left alone. Use --no-deps to
+start only the given projects.
+
 Examples:
+  dev run
   dev run zensight-fe
-  dev run api-server`,
-	Args: cobra.ExactArgs(1),
-	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		// Get list of registered projects for completion
-		projects, err := storage.ListProjects()
-		if err != nil {
-			return nil, cobra.ShellCompDirectiveNoFileComp
-		}
-		
-		var projectNames []string
-		for _, project := range projects {
-			// Only show projects that match the current input
-			if toComplete == "" || len(project.Name) >= len(toComplete) && project.Name[:len(toComplete)] == toComplete {
-				projectNames = append(projectNames, project.Name)
+  dev run api-server
+  dev run api-server test
+  dev run api-server --env PORT=4000 --env DEBUG=1
+  dev run frontend api-server worker
+  dev run frontend api-server:debug --kill-others
+  dev run @shop
+  dev run --group shop
+  dev run --tag backend --tag '!legacy'
+  dev run api-server --watch
+  dev run frontend --no-deps`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeRunArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		store := getStore()
+		group := groupFilter(cmd, store)
+		tags := tagFilterFlag(cmd)
+		if len(args) == 0 {
+			selected, err := selectedProjects(store, group, tags)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
 			}
+			args = selected
 		}
-		
-		// Return with NoSpace directive to prevent adding space after completion
-		return projectNames, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
-	},
-	Run: func(cmd *cobra.Command, args []string) {
-		name := args[0]
-		
-		// Get project details
-		project, err := storage.GetProject(name)
+
+		targets, err := resolveRunTargets(store, args)
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		
-		// Check if directory still exists
-		if _, err := os.Stat(project.Path); os.IsNotExist(err) {
-			fmt.Printf("Error: Project directory '%s' no longer exists\n", project.Path)
-			os.Exit(1)
+		for _, target := range targets {
+			if group != nil && !group.Has(target.project.Name) {
+				fmt.Printf("Error: project '%s' is not in group '%s'\n", target.project.Name, group.Name)
+				os.Exit(1)
+			}
+			if !tags.match(target.project) {
+				fmt.Printf("Error: project '%s' does not match tags %s\n", target.project.Name, tags)
+				os.Exit(1)
+			}
 		}
-		
-		fmt.Printf("🚀 Starting dev server for '%s'...\n", name)
-		fmt.Printf("   Path: %s\n", project.Path)
-		fmt.Printf("   Command: %s\n", project.Command)
-		if project.Description != "" {
-			fmt.Printf("   Description: %s\n", project.Description)
-		}
-		fmt.Println()
-		
-		// Check if command contains environment variables or shell features
-		// If it does, use shell execution; otherwise use direct execution
-		var execCmd *exec.Cmd
-		
-		if strings.Contains(project.Command, "=") || strings.Contains(project.Command, "&&") || 
-		   strings.Contains(project.Command, "||") || strings.Contains(project.Command, "|") ||
-		   strings.Contains(project.Command, ">") || strings.Contains(project.Command, "<") ||
-		   strings.Contains(project.Command, "$") {
-			// Use shell execution for commands with environment variables or shell features
-			var shell string
-			var shellArgs []string
-			
-			if runtime.GOOS == "windows" {
-				shell = "cmd"
-				shellArgs = []string{"/C", project.Command}
-			} else {
-				shell = "sh"
-				shellArgs = []string{"-c", project.Command}
-			}
-			
-			execCmd = exec.Command(shell, shellArgs...)
-		} else {
-			// Parse command and arguments for simple commands
-			parts := strings.Fields(project.Command)
-			if len(parts) == 0 {
-				fmt.Printf("Error: Invalid command '%s'\n", project.Command)
+
+		// Resolve the environment overrides given with --env
+		overrides := map[string]string{}
+		envFlags, _ := cmd.Flags().GetStringArray("env")
+		for _, value := range envFlags {
+			key, val, err := parseAssignment("env", value)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			overrides[key] = val
+		}
+
+		noLog, _ := cmd.Flags().GetBool("no-log")
+		grace, _ := cmd.Flags().GetDuration("grace")
+
+		if noDeps, _ := cmd.Flags().GetBool("no-deps"); !noDeps {
+			if targets, err = withDependencies(store, targets); err != nil {
+				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
-			execCmd = exec.Command(parts[0], parts[1:]...)
 		}
-		
-		execCmd.Dir = project.Path
+
+		for i := range targets {
+			targets[i].watch = watching(cmd, targets[i].project)
+		}
+
+		for _, target := range targets {
+			if target.isServer() {
+				checkPorts(target.project)
+			}
+		}
+
+		// Let dev show tell that the dev servers are running
+		for _, target := range targets {
+			if target.isServer() {
+				if release, err := storage.MarkRunning(target.project.Name); err == nil {
+					defer release()
+				}
+			}
+		}
+
+		if len(targets) == 1 {
+			runSingle(targets[0], overrides, grace, !noLog)
+			return
+		}
+
+		killOthers, _ := cmd.Flags().GetBool("kill-others")
+		runMultiple(targets, overrides, grace, killOthers, !noLog)
+	},
+}
+
+// runTarget is a project task selected on the dev run command line
+type runTarget struct {
+	project  *models.Project
+	taskName string
+	task     models.Task
+	// watch restarts the command when the project's files change
+	watch bool
+	// dependsOn labels the targets of the same run that have to be ready
+	// before this one starts
+	dependsOn []string
+}
+
+// label names the target in output, e.g. "api" or "api:test"
+func (t runTarget) label() string {
+	if t.taskName == "" {
+		return t.project.Name
+	}
+	return t.project.Name + ":" + t.taskName
+}
+
+// isServer reports whether the target runs the project's dev server, as
+// opposed to another task. Ports and readiness checks only apply to the
+// server.
+func (t runTarget) isServer() bool {
+	return t.taskName == "" || t.taskName == models.DefaultTask
+}
+
+// selectedProjects names the projects chosen with --group and --tag when no
+// project is given, in the group's order when there is a group. Without
+// either it names the project the current directory belongs to.
+func selectedProjects(store storage.Store, group *models.Group, tags tagFilter) ([]string, error) {
+	if group == nil && tags.empty() {
+		project, err := currentProject(store)
+		if err != nil {
+			return nil, fmt.Errorf("%v; specify a project to run, or select projects with --group or --tag", err)
+		}
+		return []string{project.Name}, nil
+	}
+
+	projects, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	if group != nil {
+		var members []models.Project
+		for _, name := range group.Projects {
+			for _, project := range projects {
+				if project.Name == name {
+					members = append(members, project)
+				}
+			}
+		}
+		projects = members
+	}
+
+	var names []string
+	for _, project := range tags.apply(projects) {
+		names = append(names, project.Name)
+	}
+	if len(names) == 0 {
+		if tags.empty() {
+			return nil, fmt.Errorf("group '%s' has no projects", group.Name)
+		}
+		return nil, fmt.Errorf("no projects match tags %s", tags)
+	}
+	return names, nil
+}
+
+// expandGroups replaces @group arguments with the group's projects, leaving
+// out projects that are already listed
+func expandGroups(store storage.Store, args []string) ([]string, error) {
+	var expanded []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, groupPrefix) {
+			if !containsString(expanded, arg) {
+				expanded = append(expanded, arg)
+			}
+			continue
+		}
+
+		group, err := store.GetGroup(strings.TrimPrefix(arg, groupPrefix))
+		if err != nil {
+			return nil, err
+		}
+		if len(group.Projects) == 0 {
+			return nil, fmt.Errorf("group '%s' has no projects", group.Name)
+		}
+		for _, name := range group.Projects {
+			if !containsString(expanded, name) {
+				expanded = append(expanded, name)
+			}
+		}
+	}
+	return expanded, nil
+}
+
+// resolveRunTargets turns dev run arguments into targets. A single project
+// may be followed by one of its task names; otherwise every argument is a
+// project, optionally written as project:task, or @group for every project
+// of a group.
+func resolveRunTargets(store storage.Store, args []string) ([]runTarget, error) {
+	withTask := len(args) == 2 && !strings.HasPrefix(args[0], groupPrefix) && !strings.HasPrefix(args[1], groupPrefix)
+	args, err := expandGroups(store, args)
+	if err != nil {
+		return nil, err
+	}
+	// Duplicates are dropped, so dev run api api leaves a single project
+	withTask = withTask && len(args) == 2
+
+	if withTask {
+		project, err := store.Get(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := project.Tasks[args[1]]; ok || args[1] == models.DefaultTask {
+			target, err := newRunTarget(project, args[1])
+			if err != nil {
+				return nil, err
+			}
+			return []runTarget{target}, nil
+		}
+		if _, err := store.Get(args[1]); err != nil && !strings.Contains(args[1], ":") {
+			return nil, fmt.Errorf("'%s' is neither a task of '%s' nor a registered project", args[1], args[0])
+		}
+	}
+
+	var targets []runTarget
+	for _, arg := range args {
+		name, taskName, _ := strings.Cut(arg, ":")
+		project, err := store.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		target, err := newRunTarget(project, taskName)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// dependencyProbeTimeout bounds the check whether a dependency is already
+// running
+const dependencyProbeTimeout = 5 * time.Second
+
+// withDependencies puts the dev servers of the projects the targets depend
+// on, directly or not, in front of them in dependency order. Dependencies
+// that are already running are left out.
+func withDependencies(store storage.Store, targets []runTarget) ([]runTarget, error) {
+	projects, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	deps := graph.New(projects)
+
+	var roots []string
+	servers := map[string]bool{}
+	for _, target := range targets {
+		roots = append(roots, target.project.Name)
+		if target.isServer() {
+			servers[target.project.Name] = true
+		}
+	}
+	order, err := deps.Order(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]*models.Project{}
+	for i := range projects {
+		byName[projects[i].Name] = &projects[i]
+	}
+
+	// A target only pulls in its own dev server when another target needs it
+	needed := map[string]bool{}
+	for _, name := range order {
+		for _, dep := range byName[name].DependsOn {
+			needed[dep] = true
+		}
+	}
+
+	var all []runTarget
+	started := map[string]bool{}
+	for _, name := range order {
+		if servers[name] {
+			started[name] = true
+			continue
+		}
+		if !needed[name] {
+			continue
+		}
+		project := byName[name]
+		if serverRunning(project) {
+			fmt.Printf("🔗 Dependency '%s' is already running\n", name)
+			continue
+		}
+		target, err := newRunTarget(project, "")
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, target)
+		started[name] = true
+	}
+	all = append(all, targets...)
+
+	for i := range all {
+		all[i].dependsOn = nil
+		for _, dep := range all[i].project.DependsOn {
+			if started[dep] {
+				all[i].dependsOn = append(all[i].dependsOn, dep)
+			}
+		}
+	}
+	return all, nil
+}
+
+// serverRunning guesses whether a project's dev server is already running:
+// its readiness check passes or, without a usable check, all its declared
+// ports are in use
+func serverRunning(project *models.Project) bool {
+	if project.Ready != nil && project.Ready.Log == "" {
+		env, err := runner.BuildEnv(project, nil)
+		if err != nil {
+			return false
+		}
+		readiness, err := runner.NewReadiness(project, env)
+		if err != nil {
+			return false
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), dependencyProbeTimeout)
+		defer cancel()
+		return readiness.Check(ctx) == nil
+	}
+
+	if len(project.Ports) == 0 {
+		return false
+	}
+	for _, port := range project.Ports {
+		if !ports.Check(port).InUse {
+			return false
+		}
+	}
+	return true
+}
+
+func newRunTarget(project *models.Project, taskName string) (runTarget, error) {
+	task, err := project.GetTask(taskName)
+	if err != nil {
+		return runTarget{}, err
+	}
+	return runTarget{project: project, taskName: taskName, task: task}, nil
+}
+
+// prepareCommand checks the project directory and builds the command for a
+// target with its full environment
+func prepareCommand(target runTarget, overrides map[string]string) (*exec.Cmd, error) {
+	// Check if directory still exists
+	if _, err := os.Stat(target.project.Path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("project directory '%s' no longer exists", target.project.Path)
+	}
+
+	env, err := runner.BuildEnv(target.project, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load environment for '%s': %v", target.project.Name, err)
+	}
+
+	return runner.Command(target.project, target.task, env)
+}
+
+// openRunLog opens the log file that captures a target's output. Logging is
+// best effort: when the file cannot be opened a warning is printed and the
+// run continues without it.
+func openRunLog(target runTarget) *logs.Writer {
+	logsDir, err := storage.GetLogsDir()
+	if err == nil {
+		var logWriter *logs.Writer
+		if logWriter, err = logs.Open(logsDir, target.label()); err == nil {
+			return logWriter
+		}
+	}
+	fmt.Printf("⚠️  Output of '%s' will not be logged: %v\n", target.label(), err)
+	return nil
+}
+
+// gracePeriod picks the stop grace period for a project: the --grace flag,
+// then the project's stop_timeout, then the default
+func gracePeriod(project *models.Project, flag time.Duration) time.Duration {
+	if flag > 0 {
+		return flag
+	}
+	if project.StopTimeout > 0 {
+		return project.StopTimeout.Std()
+	}
+	return runner.StopTimeout
+}
+
+// runSingle runs one target in the foreground, attached to the terminal. The
+// command is started again according to the project's restart policy and,
+// when watching, whenever the project's files change. dev exits with the
+// command's exit code.
+func runSingle(target runTarget, overrides map[string]string, grace time.Duration, logOutput bool) {
+	project := target.project
+
+	if target.taskName != "" {
+		fmt.Printf("🚀 Running task '%s' for '%s'...\n", target.taskName, project.Name)
+	} else {
+		fmt.Printf("🚀 Starting dev server for '%s'...\n", project.Name)
+	}
+	fmt.Printf("   Path: %s\n", project.Path)
+	fmt.Printf("   Command: %s\n", target.task.Command)
+	if project.Description != "" {
+		fmt.Printf("   Description: %s\n", project.Description)
+	}
+	if len(project.Ports) > 0 {
+		fmt.Printf("   Ports: %s\n", formatPorts(project.Ports))
+	}
+	if project.Restart != "" && project.Restart != models.RestartNever {
+		fmt.Printf("   Restart: %s\n", project.Restart)
+	}
+
+	var watcher *watch.Watcher
+	if target.watch {
+		watcher = openWatcher(project)
+		defer watcher.Close()
+		fmt.Printf("   Watching: %s (%s)\n", project.Path, watcher.Mode())
+	}
+	fmt.Println()
+
+	var logWriter *logs.Writer
+	if logOutput {
+		if logWriter = openRunLog(target); logWriter != nil {
+			defer logWriter.Close()
+		}
+	}
+
+	// note prints a message about the run and records it in the log
+	note := func(icon, message string) {
+		fmt.Printf("\n%s %s\n\n", icon, message)
+		if logWriter != nil {
+			logWriter.Notef("%s", message)
+		}
+	}
+
+	// When commands are restarted dev keeps the terminal to itself, so
+	// that Ctrl-C ends the restart loop instead of just the current run
+	restarter := runner.NewRestarter(project)
+	interactive := !restarter.Enabled() && watcher == nil
+	ctx := context.Background()
+	if !interactive {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		defer stop()
+	}
+
+	var readiness *runner.Readiness
+	for {
+		execCmd, err := prepareCommand(target, overrides)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if readiness == nil && target.isServer() {
+			readiness = newReadiness(project, execCmd)
+		}
+
 		execCmd.Stdout = os.Stdout
 		execCmd.Stderr = os.Stderr
-		execCmd.Stdin = os.Stdin
-		
+		if interactive {
+			execCmd.Stdin = os.Stdin
+		}
+
+		flushLog := func() {}
+		if logWriter != nil {
+			logWriter.MarkStart(target.task.Command)
+			execCmd.Stdout, execCmd.Stderr, flushLog = logWriter.Tee(os.Stdout, os.Stderr)
+		}
+		if readiness != nil {
+			readiness.Reset()
+			execCmd.Stdout = readiness.Tee(execCmd.Stdout)
+			execCmd.Stderr = readiness.Tee(execCmd.Stderr)
+		}
+
+		// Stop the command when watched files change
+		runCtx, cancelRun := context.WithCancel(ctx)
+		changes := make(chan []string, 1)
+		if watcher != nil {
+			go func() {
+				select {
+				case changed := <-watcher.Changes():
+					changes <- changed
+					cancelRun()
+				case <-runCtx.Done():
+				}
+			}()
+		}
+
 		// Start the command
-		if err := execCmd.Run(); err != nil {
+		startedAt := time.Now()
+		if readiness != nil {
+			go func() {
+				err := readiness.Wait(runCtx)
+				switch {
+				case err == nil:
+					note("✅", fmt.Sprintf("'%s' ready in %s", target.label(), time.Since(startedAt).Round(100*time.Millisecond)))
+				case errors.Is(err, runner.ErrNotReady):
+					note("⚠️ ", fmt.Sprintf("'%s' %v", target.label(), err))
+				}
+			}()
+		}
+		err = runner.RunContext(runCtx, execCmd, gracePeriod(project, grace))
+		endedAt := time.Now()
+		cancelRun()
+		flushLog()
+		recordRun(project.Name, startedAt, endedAt, execCmd)
+
+		if ctx.Err() == nil {
+			select {
+			case changed := <-changes:
+				restarter.Reset()
+				note("🔄", fmt.Sprintf("%s changed, restarting '%s'", watch.Describe(changed), target.label()))
+				continue
+			default:
+			}
+
+			code := runner.ExitCode(execCmd)
+			delay, restart := restarter.Next(err, endedAt.Sub(startedAt))
+			switch {
+			case restart:
+				note("🔁", fmt.Sprintf("'%s' exited with code %d, restarting in %s (%s)", target.label(), code, delay, restarter.Describe()))
+			case restarter.Enabled() && restarter.Attempts() > 0 && runner.IsExitError(err):
+				note("❌", fmt.Sprintf("'%s' exited with code %d, giving up after %d restarts", target.label(), code, restarter.Attempts()))
+			}
+
+			var wake <-chan time.Time
+			if restart {
+				wake = time.After(delay)
+			}
+			var changed <-chan []string
+			if watcher != nil {
+				changed = watcher.Changes()
+				if !restart {
+					fmt.Printf("⏸  '%s' exited with code %d, waiting for changes...\n", target.label(), code)
+				}
+			}
+
+			if restart || watcher != nil {
+				select {
+				case <-wake:
+					continue
+				case files := <-changed:
+					restarter.Reset()
+					note("🔄", fmt.Sprintf("%s changed, restarting '%s'", watch.Describe(files), target.label()))
+					continue
+				case <-ctx.Done():
+				}
+			}
+		}
+
+		if err != nil {
+			if runner.IsExitError(err) {
+				os.Exit(runner.ExitCode(execCmd))
+			}
 			fmt.Printf("Error running command: %v\n", err)
 			os.Exit(1)
 		}
-	},
+		return
+	}
+}
+
+// newReadiness returns the readiness probe of a project run with execCmd, or
+// nil when it has none, exiting on an invalid probe
+func newReadiness(project *models.Project, execCmd *exec.Cmd) *runner.Readiness {
+	readiness, err := runner.NewReadiness(project, execCmd.Env)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return readiness
+}
+
+// openWatcher starts watching a project's files according to its watch
+// settings, exiting on failure
+func openWatcher(project *models.Project) *watch.Watcher {
+	opts := watch.Options{Gitignore: project.Watch.RespectsGitignore()}
+	if project.Watch != nil {
+		opts.Include = project.Watch.Include
+		opts.Exclude = project.Watch.Exclude
+		opts.Debounce = project.Watch.Debounce.Std()
+	}
+
+	watcher, err := watch.New(project.Path, opts)
+	if err != nil {
+		fmt.Printf("Error: failed to watch '%s': %v\n", project.Path, err)
+		os.Exit(1)
+	}
+	return watcher
+}
+
+// watching reports whether a project's files should be watched: the
+// --watch flag when given, otherwise the project's watch setting
+func watching(cmd *cobra.Command, project *models.Project) bool {
+	if cmd.Flags().Changed("watch") {
+		watchFlag, _ := cmd.Flags().GetBool("watch")
+		return watchFlag
+	}
+	return project.Watch != nil && project.Watch.Enabled
+}
+
+// runMultiple starts every target concurrently with prefixed output and waits
+// for all of them. Ctrl-C shuts them all down.
+func runMultiple(targets []runTarget, overrides map[string]string, grace time.Duration, killOthers, logOutput bool) {
+	var procs []runner.Process
+	for _, target := range targets {
+		execCmd, err := prepareCommand(target, overrides)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		proc := runner.Process{
+			Name:    target.label(),
+			Cmd:     execCmd,
+			Command: target.task.Command,
+			Grace:   gracePeriod(target.project, grace),
+
+			DependsOn: target.dependsOn,
+		}
+		if target.isServer() {
+			proc.Ready = newReadiness(target.project, execCmd)
+		}
+		target := target
+		proc.NewCmd = func() (*exec.Cmd, error) { return prepareCommand(target, overrides) }
+		if restarter := runner.NewRestarter(target.project); restarter.Enabled() {
+			proc.Restart = restarter
+		}
+		if target.watch {
+			proc.Watch = openWatcher(target.project)
+			defer proc.Watch.Close()
+		}
+		if logOutput {
+			if proc.Log = openRunLog(target); proc.Log != nil {
+				defer proc.Log.Close()
+			}
+		}
+		procs = append(procs, proc)
+	}
+
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.label()
+	}
+	fmt.Printf("🚀 Starting %d projects: %s\n", len(targets), strings.Join(names, ", "))
+	fmt.Println("   Press Ctrl-C to stop them all")
+	fmt.Println()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	results := runner.RunAll(ctx, procs, runner.MultiOptions{
+		Output:     os.Stdout,
+		Color:      runner.UseColor(os.Stdout),
+		KillOthers: killOthers,
+	})
+
+	// Exit with the code of the first project that failed
+	code := 0
+	for i, result := range results {
+		if !result.StartedAt.IsZero() {
+			recordRun(targets[i].project.Name, result.StartedAt, result.EndedAt, result.Cmd)
+		}
+		if result.Failed() && code == 0 {
+			code = 1
+			if runner.IsExitError(result.Err) {
+				code = runner.ExitCode(result.Cmd)
+			}
+		}
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// recordRun stores the outcome of a finished run when the backend keeps run
+// history. Failing to record is not worth failing the run over.
+func recordRun(name string, startedAt, endedAt time.Time, execCmd *exec.Cmd) {
+	history, err := storage.History(getStore())
+	if err != nil {
+		return
+	}
+
+	history.RecordRun(models.Run{
+		Project:   name,
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+		ExitCode:  runner.ExitCode(execCmd),
+		Duration:  endedAt.Sub(startedAt),
+	})
+}
+
+// completeRunArgs completes project names, plus the first project's task
+// names for the second argument
+func completeRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.HasPrefix(toComplete, groupPrefix) {
+		groups, directive := completeGroupNames(cmd, args, strings.TrimPrefix(toComplete, groupPrefix))
+		for i := range groups {
+			groups[i] = groupPrefix + groups[i]
+		}
+		return groups, directive
+	}
+
+	names, directive := completeProjectNames(cmd, args, toComplete)
+	if len(args) != 1 {
+		return names, directive
+	}
+
+	tasks, _ := completeProjectTasks(cmd, args, toComplete)
+	return append(tasks, names...), directive
 }
 
 func init() {
+	runCmd.Flags().StringArrayP("env", "e", nil, "Set an environment variable for this run as KEY=VALUE (repeatable)")
+	runCmd.Flags().Duration("grace", 0, "How long to wait for the server to exit after Ctrl-C before killing it (default: project stop_timeout or 10s)")
+	runCmd.Flags().Bool("no-log", false, "Do not capture output in the log files read by 'dev logs'")
+	runCmd.Flags().BoolP("watch", "w", false, "Restart the command when files in the project directory change")
+	runCmd.Flags().String("group", "", "Only run projects in this group; with no names, run the whole group")
+	runCmd.RegisterFlagCompletionFunc("group", completeGroupNames)
+	runCmd.Flags().StringSlice("tag", nil, "Only run projects with this tag, or without it as !tag; with no names, run every match (repeatable)")
+	runCmd.RegisterFlagCompletionFunc("tag", completeTagFilter)
+	runCmd.Flags().Bool("no-deps", false, "Do not start the projects the given projects depend on")
+	runCmd.Flags().Bool("kill-others", false, "When running several projects, stop all of them as soon as one exits")
 	rootCmd.AddCommand(runCmd)
 }
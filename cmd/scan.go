@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"dev-util/detect"
+	"dev-util/models"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+)
+
+// scanSkipDirs are never descended into by dev scan: they hold dependencies
+// or build output rather than projects of their own
+var scanSkipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+	"venv":         true,
+	"__pycache__":  true,
+}
+
+// scanResult is a project found by dev scan
+type scanResult struct {
+	name      string
+	path      string
+	candidate detect.Candidate
+}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [dir]",
+	Short: "Find projects in a directory and register them",
+	Long: `Look through a directory for projects and offer to register the ones that
+are not registered yet. dev scan descends --depth levels below the directory,
+skipping hidden directories and dependency folders such as node_modules, and
+does not look inside a project once it has found one.
+
+Projects are recognised by their files:
+  package.json    the dev or start script, run with npm, yarn, pnpm or bun
+                  depending on the lockfile
+  go.mod          go run for a main package at the root or under cmd/
+  Cargo.toml      cargo run
+  manage.py       python manage.py runserver
+  Gemfile         bin/dev or the Rails server, for Rails apps
+  Procfile        the web process
+  Makefile        a dev, run, serve, server, start, up or watch target
+  compose.yaml    docker compose up (also docker-compose.yml)
+
+Each project is named after its directory. Pick the ones to add from the
+list, or pass --yes to add them all.
+
+Examples:
+  dev scan ~/code
+  dev scan ~/code --depth 3
+  dev scan . --yes`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		root := "."
+		if len(args) == 1 {
+			root = args[0]
+		}
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			fmt.Printf("Error: Invalid path '%s': %v\n", root, err)
+			os.Exit(1)
+		}
+		if info, err := os.Stat(absRoot); err != nil || !info.IsDir() {
+			fmt.Printf("Error: Directory '%s' does not exist\n", absRoot)
+			os.Exit(1)
+		}
+
+		depth, _ := cmd.Flags().GetInt("depth")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		store := getStore()
+		projects, err := store.List()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("🔍 Scanning %s...\n", absRoot)
+		results, registered := scanProjects(absRoot, depth, projects)
+		if registered > 0 {
+			fmt.Printf("   Skipped %d project(s) that are already registered\n", registered)
+		}
+		if len(results) == 0 {
+			fmt.Println("No new projects found.")
+			return
+		}
+
+		fmt.Printf("\n📦 Found %d new project(s)\n\n", len(results))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCOMMAND\tFOUND IN\tPATH")
+		fmt.Fprintln(w, "----\t-------\t--------\t----")
+		for _, result := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.name, result.candidate.Command, result.candidate.Source, relativePath(absRoot, result.path))
+		}
+		w.Flush()
+		fmt.Println()
+
+		selected := results
+		if !yes {
+			selected = selectScanResults(absRoot, results)
+			if len(selected) == 0 {
+				fmt.Println("Operation cancelled.")
+				return
+			}
+		}
+
+		failed := false
+		for _, result := range selected {
+			if err := store.Add(models.Project{
+				Name:    result.name,
+				Path:    result.path,
+				Command: result.candidate.Command,
+			}); err != nil {
+				fmt.Printf("❌ Failed to add '%s': %v\n", result.name, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("✅ Successfully added project '%s'\n", result.name)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// scanProjects walks root up to depth levels down and returns the projects
+// found in it that are not registered yet, along with how many were skipped
+// because they are
+func scanProjects(root string, depth int, projects []models.Project) ([]scanResult, int) {
+	registeredPaths := map[string]bool{}
+	taken := map[string]bool{}
+	for _, project := range projects {
+		registeredPaths[filepath.Clean(project.Path)] = true
+		taken[project.Name] = true
+	}
+
+	var results []scanResult
+	registered := 0
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than ending the scan
+			if entry != nil && entry.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(entry.Name(), ".") || scanSkipDirs[entry.Name()]) {
+			return filepath.SkipDir
+		}
+
+		candidate, ok := detect.Best(path)
+		if ok {
+			if registeredPaths[path] {
+				registered++
+			} else {
+				name := detect.SuggestName(path, func(name string) bool { return taken[name] })
+				taken[name] = true
+				results = append(results, scanResult{name: name, path: path, candidate: candidate})
+			}
+			return filepath.SkipDir
+		}
+
+		if levels(root, path) >= depth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return results, registered
+}
+
+// levels returns how many directories path is below root
+func levels(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// relativePath shortens path for display when it is inside root
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+// selectScanResults asks which of the found projects to add, all of them
+// selected to begin with
+func selectScanResults(root string, results []scanResult) []scanResult {
+	options := make([]string, len(results))
+	for i, result := range results {
+		options[i] = fmt.Sprintf("%s (%s) in %s", result.name, result.candidate.Command, relativePath(root, result.path))
+	}
+
+	var chosen []int
+	prompt := &survey.MultiSelect{
+		Message:  "Which projects should be added?",
+		Options:  options,
+		Default:  options,
+		PageSize: 15,
+	}
+	if err := survey.AskOne(prompt, &chosen); err != nil {
+		fmt.Printf("Error during interactive setup: %v\n", err)
+		os.Exit(1)
+	}
+
+	selected := make([]scanResult, len(chosen))
+	for i, index := range chosen {
+		selected[i] = results[index]
+	}
+	return selected
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+	scanCmd.Flags().Int("depth", 2, "How many directory levels below dir to look for projects")
+	scanCmd.Flags().BoolP("yes", "y", false, "Add every project found without asking")
+}
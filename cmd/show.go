@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"dev-util/daemon"
+	"dev-util/logs"
+	"dev-util/models"
+	"dev-util/ports"
+	"dev-util/storage"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Ways a project's dev server can be running, as reported by dev show
+const (
+	runningUnderDaemon = "daemon"
+	runningUnderRun    = "dev run"
+)
+
+// showOutput is what dev show writes for --output json and yaml and passes
+// to --template: the fields of dev list plus the last run and live status
+type showOutput struct {
+	projectOutput `yaml:",inline"`
+	LastRun       *runOutput   `json:"last_run" yaml:"last_run"`
+	Status        statusOutput `json:"status" yaml:"status"`
+}
+
+type runOutput struct {
+	StartedAt time.Time  `json:"started_at" yaml:"started_at"`
+	EndedAt   *time.Time `json:"ended_at" yaml:"ended_at"`
+	ExitCode  *int       `json:"exit_code" yaml:"exit_code"`
+}
+
+type statusOutput struct {
+	DirectoryExists bool               `json:"directory_exists" yaml:"directory_exists"`
+	Git             *gitOutput         `json:"git" yaml:"git"`
+	Ports           []portStatusOutput `json:"ports" yaml:"ports"`
+	Running         bool               `json:"running" yaml:"running"`
+	RunningUnder    string             `json:"running_under" yaml:"running_under"`
+	PID             int                `json:"pid" yaml:"pid"`
+}
+
+type gitOutput struct {
+	Branch string `json:"branch" yaml:"branch"`
+	Dirty  bool   `json:"dirty" yaml:"dirty"`
+}
+
+type portStatusOutput struct {
+	Port    int    `json:"port" yaml:"port"`
+	InUse   bool   `json:"in_use" yaml:"in_use"`
+	PID     int    `json:"pid" yaml:"pid"`
+	Process string `json:"process" yaml:"process"`
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show the details of a project",
+	Long: `Show everything dev knows about a registered project: its command and
+tasks, environment, tags, groups, ports, dependencies and when it was
+created, updated and last run.
+
+It also checks the project's live status: whether its directory exists, the
+git branch and whether the working tree has uncommitted changes, whether its
+declared ports are in use and by what, and whether its dev server is
+currently running under dev run or the background daemon.
+
+Use --output json, yaml or csv, or --template, for output meant for scripts.
+The fields are those of 'dev list --output json' plus last_run and status;
+csv output has the dev list columns only.
+
+Examples:
+  dev show api-server
+  dev show api-server --output json
+  dev show api-server --template '{{.Status.Running}}'`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	Annotations:       map[string]string{structuredOutputAnnotation: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		store := getStore()
+		project, err := store.Get(args[0])
+		if err != nil {
+			fail(err)
+		}
+		groups, err := store.Groups()
+		if err != nil {
+			fail(err)
+		}
+
+		out := showOutput{
+			projectOutput: newProjectOutput(project, groups),
+			LastRun:       lastRun(store, project.Name),
+			Status:        projectStatus(project),
+		}
+
+		switch outputFormat() {
+		case outputJSON, outputYAML:
+			writeStructured(out)
+			return
+		case outputCSV:
+			w := csv.NewWriter(os.Stdout)
+			w.Write(projectCSVHeader)
+			w.Write(projectCSVRow(out.projectOutput))
+			w.Flush()
+			return
+		case outputTemplate:
+			writeTemplate(out)
+			return
+		}
+
+		printProject(project, out)
+	},
+}
+
+// printProject writes the human-readable form of dev show
+func printProject(project *models.Project, out showOutput) {
+	fmt.Printf("📁 Project '%s'\n", project.Name)
+	fmt.Printf("   Path: %s\n", project.Path)
+	fmt.Printf("   Command: %s\n", project.Command)
+	if project.Description != "" {
+		fmt.Printf("   Description: %s\n", project.Description)
+	}
+	if len(out.Tags) > 0 {
+		fmt.Printf("   Tags: %s\n", strings.Join(out.Tags, ", "))
+	}
+	if len(out.Groups) > 0 {
+		fmt.Printf("   Groups: %s\n", strings.Join(out.Groups, ", "))
+	}
+	printTasks(project)
+
+	if len(out.Env) > 0 {
+		keys := make([]string, 0, len(out.Env))
+		for key := range out.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Println("   Env:")
+		for _, key := range keys {
+			fmt.Printf("     %s=%s\n", key, out.Env[key])
+		}
+	}
+	fmt.Printf("   Env files: %s\n", strings.Join(out.EnvFiles, ", "))
+	fmt.Printf("   Shell: %s\n", out.Shell)
+	fmt.Printf("   Restart: %s\n", out.Restart)
+	if out.Watch {
+		fmt.Println("   Watch: on")
+	}
+	if out.Ready != "" {
+		fmt.Printf("   Ready: %s\n", out.Ready)
+	}
+	if len(out.DependsOn) > 0 {
+		fmt.Printf("   Depends on: %s\n", strings.Join(out.DependsOn, ", "))
+	}
+
+	fmt.Printf("   Created: %s\n", project.CreatedAt.Local().Format("2006-01-02 15:04"))
+	if out.UpdatedAt != nil {
+		fmt.Printf("   Updated: %s\n", out.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	switch {
+	case out.LastRun == nil:
+		fmt.Println("   Last run: never")
+	case out.LastRun.ExitCode == nil:
+		fmt.Printf("   Last run: %s\n", out.LastRun.StartedAt.Local().Format("2006-01-02 15:04"))
+	default:
+		fmt.Printf("   Last run: %s (exit %d)\n", out.LastRun.StartedAt.Local().Format("2006-01-02 15:04"), *out.LastRun.ExitCode)
+	}
+
+	status := out.Status
+	fmt.Println()
+	fmt.Println("📋 Status")
+	if status.DirectoryExists {
+		fmt.Println("   Directory: ✅ exists")
+	} else {
+		fmt.Println("   Directory: ❌ missing")
+	}
+	if status.Git != nil {
+		if status.Git.Dirty {
+			fmt.Printf("   Git: %s (uncommitted changes)\n", status.Git.Branch)
+		} else {
+			fmt.Printf("   Git: %s (clean)\n", status.Git.Branch)
+		}
+	}
+	for _, port := range status.Ports {
+		switch {
+		case !port.InUse:
+			fmt.Printf("   Port %d: free\n", port.Port)
+		case port.PID != 0:
+			owner := ports.Owner{PID: port.PID, Name: port.Process}
+			fmt.Printf("   Port %d: in use by %s\n", port.Port, owner.String())
+		default:
+			fmt.Printf("   Port %d: in use\n", port.Port)
+		}
+	}
+	if status.Running {
+		fmt.Printf("   Running: 🚀 yes, under %s (pid %d)\n", status.RunningUnder, status.PID)
+	} else {
+		fmt.Println("   Running: no")
+	}
+}
+
+// lastRun returns the most recent run of a project, from the run history
+// where the backend keeps one and from its logs otherwise. It returns nil
+// when the project has never run.
+func lastRun(store storage.Store, name string) *runOutput {
+	if history, err := storage.History(store); err == nil {
+		if runs, err := history.Runs(name, 1); err == nil && len(runs) > 0 {
+			run := runs[0]
+			return &runOutput{StartedAt: run.StartedAt, EndedAt: &run.EndedAt, ExitCode: &run.ExitCode}
+		}
+	}
+
+	// Logs only record when a run started
+	logsDir, err := storage.GetLogsDir()
+	if err != nil {
+		return nil
+	}
+	entries, _ := logs.Read(logsDir, name, logs.Filter{})
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].IsStart() {
+			return &runOutput{StartedAt: entries[i].Time}
+		}
+	}
+	return nil
+}
+
+// projectStatus checks the live state of a project on this machine
+func projectStatus(project *models.Project) statusOutput {
+	status := statusOutput{Ports: []portStatusOutput{}}
+
+	if info, err := os.Stat(project.Path); err == nil && info.IsDir() {
+		status.DirectoryExists = true
+		status.Git = gitStatus(project.Path)
+	}
+
+	for _, port := range project.Ports {
+		check := ports.Check(port)
+		out := portStatusOutput{Port: port, InUse: check.InUse}
+		if check.Owner != nil {
+			out.PID = check.Owner.PID
+			out.Process = check.Owner.Name
+		}
+		status.Ports = append(status.Ports, out)
+	}
+
+	if pid := daemonPID(project.Name); pid != 0 {
+		status.Running, status.RunningUnder, status.PID = true, runningUnderDaemon, pid
+	} else if pid := storage.RunningPID(project.Name); pid != 0 {
+		status.Running, status.RunningUnder, status.PID = true, runningUnderRun, pid
+	}
+	return status
+}
+
+// gitStatus returns the branch and dirty state of the git work tree at path,
+// or nil when path is not in one or git is not installed
+func gitStatus(path string) *gitOutput {
+	branch, err := exec.Command("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return nil
+	}
+	out := &gitOutput{Branch: strings.TrimSpace(string(branch))}
+	if out.Branch == "HEAD" {
+		if sha, err := exec.Command("git", "-C", path, "rev-parse", "--short", "HEAD").Output(); err == nil {
+			out.Branch = "detached at " + strings.TrimSpace(string(sha))
+		}
+	}
+
+	changes, err := exec.Command("git", "-C", path, "status", "--porcelain").Output()
+	if err == nil {
+		out.Dirty = len(strings.TrimSpace(string(changes))) > 0
+	}
+	return out
+}
+
+// daemonPID returns the pid of the project's dev server when the daemon
+// supervises it, or 0 when it does not or no daemon is running
+func daemonPID(name string) int {
+	stateDir, err := storage.GetStateDir()
+	if err != nil {
+		return 0
+	}
+	resp, err := daemon.NewClient(daemon.SocketPath(stateDir)).Send(daemon.Request{
+		Action: daemon.ActionStatus,
+		Names:  []string{name},
+	})
+	if err != nil {
+		return 0
+	}
+	for _, process := range resp.Processes {
+		if process.Name == name && process.State == daemon.StateRunning {
+			return process.PID
+		}
+	}
+	return 0
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+}
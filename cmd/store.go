@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"dev-util/models"
+	"dev-util/storage"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// projectStore is the backend every command reads and writes projects
+// through. It is opened lazily on first use; tests can assign a
+// storage.MemoryStore here before executing a command.
+var projectStore storage.Store
+
+// openStore returns the configured project store, opening it on first use
+func openStore() (storage.Store, error) {
+	if projectStore != nil {
+		return projectStore, nil
+	}
+
+	store, err := storage.Open()
+	if err != nil {
+		return nil, err
+	}
+	projectStore = store
+	return projectStore, nil
+}
+
+// closeStore releases the project store if the backend holds resources such
+// as a database handle
+func closeStore() {
+	if closer, ok := projectStore.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// getStore is openStore for command handlers: it reports the error and exits
+// when the store cannot be opened
+func getStore() storage.Store {
+	store, err := openStore()
+	if err != nil {
+		fail(err)
+	}
+	return store
+}
+
+// currentProject returns the registered project the working directory is in
+func currentProject(store storage.Store) (*models.Project, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the current directory: %w", err)
+	}
+	return storage.FindByPath(store, dir)
+}
+
+// completeProjectNames is a ValidArgsFunction that completes registered
+// project names
+func completeProjectNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	store, err := openStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	projects, err := store.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// Commands with a --group filter only offer the group's projects
+	if cmd != nil && cmd.Flags().Lookup("group") != nil {
+		if name, _ := cmd.Flags().GetString("group"); name != "" {
+			if group, err := store.GetGroup(name); err == nil {
+				projects = filterGroup(projects, group)
+			}
+		}
+	}
+
+	var projectNames []string
+	for _, project := range projects {
+		// Only show projects that match the current input
+		if strings.HasPrefix(project.Name, toComplete) {
+			projectNames = append(projectNames, project.Name)
+		}
+	}
+
+	// Return with NoSpace directive to prevent adding space after completion
+	return projectNames, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeFirstProjectName completes a project name for the first argument
+// only, for commands whose remaining arguments are free-form
+func completeFirstProjectName(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeProjectNames(cmd, args, toComplete)
+}
+
+// completeProjectTasks completes a project name as the first argument and
+// one of that project's task names as the second
+func completeProjectTasks(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return completeProjectNames(cmd, args, toComplete)
+	}
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	store, err := openStore()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	project, err := store.Get(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var taskNames []string
+	for _, name := range project.TaskNames() {
+		if strings.HasPrefix(name, toComplete) {
+			taskNames = append(taskNames, name)
+		}
+	}
+
+	return taskNames, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"dev-util/logs"
+	"dev-util/models"
+	"dev-util/runner"
+	"dev-util/storage"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// waitLogInterval is how often the log file is read for log probes
+const waitLogInterval = 250 * time.Millisecond
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [name...]",
+	Short: "Wait until projects are ready",
+	Long: `Block until the readiness check of every given project passes, so
+scripts can wait for servers started elsewhere with dev run or dev start.
+
+The check is configured with 'dev edit --ready-*'. A log check looks at the
+output captured since the project was last started. Projects without a
+readiness check but with declared ports are ready once their first port
+accepts connections.
+
+dev wait exits with status 1 if a project is not ready in time.
+
+Examples:
+  dev start api-server && dev wait api-server && npm test
+  dev wait api-server worker --timeout 2m`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeProjectNames,
+	Run: func(cmd *cobra.Command, args []string) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		store := getStore()
+		for _, name := range args {
+			project, err := store.Get(name)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := waitReady(ctx, project, timeout); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// waitReady runs a project's readiness check from outside the process
+// running it
+func waitReady(ctx context.Context, project *models.Project, timeout time.Duration) error {
+	check := project.Ready
+	if check == nil {
+		if len(project.Ports) == 0 {
+			return fmt.Errorf("project '%s' has no readiness check or ports; add one with 'dev edit %s --ready-http <url>'", project.Name, project.Name)
+		}
+		check = &models.ReadyCheck{TCP: strconv.Itoa(project.Ports[0])}
+	}
+
+	// Don't modify the stored project's check
+	probe := *project
+	checkCopy := *check
+	if timeout > 0 {
+		checkCopy.Timeout = models.Duration(timeout)
+	}
+	probe.Ready = &checkCopy
+
+	env, err := runner.BuildEnv(&probe, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load environment for '%s': %v", project.Name, err)
+	}
+	readiness, err := runner.NewReadiness(&probe, env)
+	if err != nil {
+		return err
+	}
+
+	if checkCopy.Log != "" {
+		stopFeeding, err := feedLogProbe(project.Name, readiness)
+		if err != nil {
+			return err
+		}
+		defer stopFeeding()
+	}
+
+	fmt.Printf("⏳ Waiting for '%s' (%s)...\n", project.Name, readiness.Describe())
+	startedAt := time.Now()
+	if err := readiness.Wait(ctx); err != nil {
+		return fmt.Errorf("'%s' %v", project.Name, err)
+	}
+	fmt.Printf("✅ '%s' is ready (waited %s)\n", project.Name, time.Since(startedAt).Round(100*time.Millisecond))
+	return nil
+}
+
+// feedLogProbe passes the output a project logged since its last start to
+// a log probe, followed by new output as it is written. The returned
+// function stops following.
+func feedLogProbe(name string, readiness *runner.Readiness) (func(), error) {
+	logsDir, err := storage.GetLogsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Start following before reading so no line falls in between. The
+	// project may not have logged anything yet, in which case only the
+	// follower sees its output.
+	follower := logs.Follow(logsDir, name, logs.Filter{})
+	entries, _ := logs.Read(logsDir, name, logs.Filter{})
+
+	current := entries
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].IsStart() {
+			current = entries[i+1:]
+			break
+		}
+	}
+	for _, entry := range current {
+		readiness.MatchLine(entry.Text)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer follower.Close()
+		ticker := time.NewTicker(waitLogInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			entries, _ := follower.Poll()
+			for _, entry := range entries {
+				readiness.MatchLine(entry.Text)
+			}
+		}
+	}()
+	return func() { close(done) }, nil
+}
+
+func init() {
+	waitCmd.Flags().Duration("timeout", 0, "How long to wait for each project (default: the check's timeout or 1m)")
+	rootCmd.AddCommand(waitCmd)
+}
@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const (
+	socketFile    = "daemon.sock"
+	daemonLogFile = "daemon.log"
+
+	// startupTimeout is how long EnsureRunning waits for a freshly spawned
+	// daemon to accept connections
+	startupTimeout = 5 * time.Second
+)
+
+// ErrNotRunning is returned when no daemon is listening on the socket
+var ErrNotRunning = errors.New("the dev daemon is not running")
+
+// SocketPath returns the daemon socket inside stateDir
+func SocketPath(stateDir string) string {
+	return filepath.Join(stateDir, socketFile)
+}
+
+// Client sends requests to the daemon over its unix socket
+type Client struct {
+	socketPath string
+}
+
+// NewClient returns a Client for the daemon listening on socketPath
+func NewClient(socketPath string) *Client {
+	return &Client{socketPath: socketPath}
+}
+
+// Send delivers req and waits for the daemon's response. Errors reported by
+// the daemon are returned together with the response, which still carries
+// the status of the processes involved.
+func (c *Client) Send(req Request) (*Response, error) {
+	conn, err := net.Dial("unix", c.socketPath)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request to daemon: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	if resp.Error != "" {
+		return &resp, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// Running reports whether a daemon answers on the socket
+func (c *Client) Running() bool {
+	conn, err := net.Dial("unix", c.socketPath)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// EnsureRunning starts a detached daemon by running the current executable
+// with args, unless one is already running. The daemon's own log goes to
+// daemon.log next to the socket.
+func (c *Client) EnsureRunning(args ...string) error {
+	if c.Running() {
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate dev executable: %w", err)
+	}
+
+	stateDir := filepath.Dir(c.socketPath)
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	logFile, err := os.OpenFile(filepath.Join(stateDir, daemonLogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open daemon log: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+	// The daemon outlives us; don't keep a handle to it
+	cmd.Process.Release()
+
+	deadline := time.Now().Add(startupTimeout)
+	for time.Now().Before(deadline) {
+		if c.Running() {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("daemon did not start within %s; see %s", startupTimeout, logFile.Name())
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package daemon
+
+import "os/exec"
+
+// detach is a no-op on platforms without sessions
+func detach(cmd *exec.Cmd) {}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package daemon
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts cmd in its own session so it survives the terminal that
+// launched it and does not receive the terminal's signals
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
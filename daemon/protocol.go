@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"dev-util/models"
+	"time"
+)
+
+// Actions understood by the daemon
+const (
+	ActionStart    = "start"
+	ActionStop     = "stop"
+	ActionRestart  = "restart"
+	ActionStatus   = "status"
+	ActionShutdown = "shutdown"
+)
+
+// Process states reported in Status
+const (
+	StateRunning = "running"
+	StateExited  = "exited"
+	StateStopped = "stopped"
+	// StateRestarting is an exited process waiting to be restarted by its
+	// restart policy
+	StateRestarting = "restarting"
+)
+
+// Spec describes a process for the daemon to supervise. The client resolves
+// the project and its environment, so the daemon does not need access to the
+// client's project store or environment.
+type Spec struct {
+	// Name identifies the process, e.g. "api" or "api:test"
+	Name    string         `json:"name"`
+	Project models.Project `json:"project"`
+	Task    string         `json:"task,omitempty"`
+	Env     []string       `json:"env"`
+	// Overrides are the --env values Env was built with, kept so dev restart
+	// can apply them again when it rebuilds the environment
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+// Request is sent by the client, one per connection, as a JSON object
+type Request struct {
+	Action string `json:"action"`
+	// Specs lists the processes to start or restart
+	Specs []Spec `json:"specs,omitempty"`
+	// Names selects processes for stop, restart and status; empty means all
+	Names []string `json:"names,omitempty"`
+}
+
+// Response is the daemon's JSON reply to a Request
+type Response struct {
+	Error     string   `json:"error,omitempty"`
+	Processes []Status `json:"processes,omitempty"`
+}
+
+// Status describes one supervised process
+type Status struct {
+	Name      string    `json:"name"`
+	Project   string    `json:"project"`
+	Command   string    `json:"command"`
+	State     string    `json:"state"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitempty"`
+	ExitCode  int       `json:"exit_code"`
+	Restarts  int       `json:"restarts"`
+	LogFile   string    `json:"log_file,omitempty"`
+	// Overrides are the --env values the process was started with
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+// Uptime returns how long the process has been running, or ran for
+func (s Status) Uptime() time.Duration {
+	if s.State == StateRunning {
+		return time.Since(s.StartedAt)
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
@@ -0,0 +1,465 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"dev-util/logs"
+	"dev-util/runner"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Server supervises dev servers on behalf of CLI clients connecting to its
+// unix socket
+type Server struct {
+	socketPath string
+	logDir     string
+
+	mu    sync.Mutex
+	procs map[string]*process
+
+	shutdown chan struct{}
+	once     sync.Once
+}
+
+// process is a supervised command and its bookkeeping
+type process struct {
+	spec      Spec
+	cmd       *exec.Cmd
+	logFile   string
+	startedAt time.Time
+	endedAt   time.Time
+	exitCode  int
+	state     string
+	restarts  int
+	// restarter applies the project's restart policy; it is carried over
+	// to the process started by an automatic restart
+	restarter *runner.Restarter
+	// done is closed once the process has exited and been reaped
+	done chan struct{}
+	// waitErr delivers the result of cmd.Wait to whoever stops the process
+	waitErr chan error
+}
+
+// NewServer returns a Server listening on socketPath once Serve is called.
+// Process output is written to rotated log files in logDir.
+func NewServer(socketPath, logDir string) *Server {
+	return &Server{
+		socketPath: socketPath,
+		logDir:     logDir,
+		procs:      map[string]*process{},
+		shutdown:   make(chan struct{}),
+	}
+}
+
+// Serve accepts connections until ctx is cancelled or a client requests a
+// shutdown. Every supervised process is stopped before Serve returns.
+func (s *Server) Serve(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.socketPath), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.MkdirAll(s.logDir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	// A socket file nobody answers on is left over from a crashed daemon
+	if conn, err := net.Dial("unix", s.socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("a daemon is already listening on %s", s.socketPath)
+	}
+	os.Remove(s.socketPath)
+
+	listener, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.socketPath, err)
+	}
+	defer os.Remove(s.socketPath)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.shutdown:
+		}
+		listener.Close()
+	}()
+
+	log.Printf("daemon listening on %s", s.socketPath)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				break
+			}
+			log.Printf("accept failed: %v", err)
+			continue
+		}
+		go s.handle(conn)
+	}
+
+	log.Printf("daemon shutting down, stopping all processes")
+	s.stopAll()
+	return nil
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		json.NewEncoder(conn).Encode(Response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	resp := s.dispatch(req)
+	json.NewEncoder(conn).Encode(resp)
+}
+
+func (s *Server) dispatch(req Request) Response {
+	var err error
+	names := req.Names
+
+	switch req.Action {
+	case ActionStart:
+		names = nil
+		for _, spec := range req.Specs {
+			if err = s.start(spec); err != nil {
+				break
+			}
+			names = append(names, spec.Name)
+		}
+	case ActionStop:
+		err = s.stop(req.Names)
+	case ActionRestart:
+		names, err = s.restart(req.Names, req.Specs)
+	case ActionStatus:
+	case ActionShutdown:
+		s.once.Do(func() { close(s.shutdown) })
+		return Response{}
+	default:
+		err = fmt.Errorf("unknown action '%s'", req.Action)
+	}
+
+	resp := Response{Processes: s.status(names)}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// start launches spec unless a process of that name is already running
+func (s *Server) start(spec Spec) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restarts := 0
+	if existing, ok := s.procs[spec.Name]; ok {
+		if existing.state == StateRunning {
+			return fmt.Errorf("'%s' is already running (pid %d)", spec.Name, existing.cmd.Process.Pid)
+		}
+		restarts = existing.restarts
+	}
+
+	proc, err := s.launch(spec)
+	if err != nil {
+		return err
+	}
+	proc.restarts = restarts
+	s.procs[spec.Name] = proc
+	return nil
+}
+
+// launch starts the command for spec with its output going to a log file.
+// The caller must hold s.mu.
+func (s *Server) launch(spec Spec) (*process, error) {
+	task, err := spec.Project.GetTask(spec.Task)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := runner.Command(&spec.Project, task, spec.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	logWriter, err := logs.Open(s.logDir, spec.Name)
+	if err != nil {
+		return nil, err
+	}
+	logWriter.MarkStart(task.Command)
+	stdout, stderr, flush := logWriter.Tee(io.Discard, io.Discard)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	grace := spec.Project.StopTimeout.Std()
+	if grace <= 0 {
+		grace = runner.StopTimeout
+	}
+	cmd.WaitDelay = grace
+
+	if err := cmd.Start(); err != nil {
+		logWriter.Close()
+		return nil, fmt.Errorf("failed to start '%s': %w", spec.Name, err)
+	}
+	log.Printf("started %s (pid %d): %s", spec.Name, cmd.Process.Pid, task.Command)
+
+	proc := &process{
+		spec:      spec,
+		cmd:       cmd,
+		logFile:   logWriter.Path(),
+		startedAt: time.Now(),
+		state:     StateRunning,
+		restarter: runner.NewRestarter(&spec.Project),
+		done:      make(chan struct{}),
+		waitErr:   make(chan error, 1),
+	}
+
+	go func() {
+		err := cmd.Wait()
+		flush()
+		if logErr := logWriter.Err(); logErr != nil {
+			log.Printf("failed to write log of %s: %v", spec.Name, logErr)
+		}
+
+		// Don't let leftover children of a crashed server hold its port
+		s.mu.Lock()
+		stopping := proc.state == StateStopped
+		s.mu.Unlock()
+		if !stopping {
+			runner.Reap(cmd, grace)
+		}
+
+		s.mu.Lock()
+		proc.endedAt = time.Now()
+		proc.exitCode = runner.ExitCode(cmd)
+		var delay time.Duration
+		restart := false
+		if proc.state == StateRunning {
+			proc.state = StateExited
+			if delay, restart = proc.restarter.Next(err, proc.endedAt.Sub(proc.startedAt)); restart {
+				proc.state = StateRestarting
+			}
+		}
+		gaveUp := proc.state == StateExited && proc.restarter.Attempts() > 0 && runner.IsExitError(err)
+		s.mu.Unlock()
+
+		log.Printf("%s (pid %d) exited with code %d", spec.Name, cmd.Process.Pid, proc.exitCode)
+		if restart {
+			message := fmt.Sprintf("exited with code %d, restarting in %s (%s)", proc.exitCode, delay, proc.restarter.Describe())
+			log.Printf("%s %s", spec.Name, message)
+			logWriter.Notef("%s", message)
+		} else if gaveUp {
+			message := fmt.Sprintf("exited with code %d, giving up after %d restarts", proc.exitCode, proc.restarter.Attempts())
+			log.Printf("%s %s", spec.Name, message)
+			logWriter.Notef("%s", message)
+		}
+		logWriter.Close()
+
+		proc.waitErr <- err
+		close(proc.done)
+
+		if restart {
+			time.Sleep(delay)
+			s.relaunch(proc)
+		}
+	}()
+
+	return proc, nil
+}
+
+// relaunch starts a process again after its restart delay, unless it was
+// stopped or replaced in the meantime
+func (s *Server) relaunch(proc *process) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if proc.state != StateRestarting || s.procs[proc.spec.Name] != proc {
+		return
+	}
+
+	next, err := s.launch(proc.spec)
+	if err != nil {
+		log.Printf("failed to restart %s: %v", proc.spec.Name, err)
+		proc.state = StateExited
+		return
+	}
+	next.restarts = proc.restarts + 1
+	next.restarter = proc.restarter
+	s.procs[proc.spec.Name] = next
+}
+
+// stop terminates the named processes, or all of them when names is empty,
+// and forgets about them
+func (s *Server) stop(names []string) error {
+	procs, err := s.lookup(names)
+	if err != nil {
+		return err
+	}
+
+	for _, proc := range procs {
+		s.terminate(proc)
+	}
+
+	s.mu.Lock()
+	for _, proc := range procs {
+		if s.procs[proc.spec.Name] == proc {
+			delete(s.procs, proc.spec.Name)
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// restart stops and starts the named processes again, or every process when
+// neither names nor specs are given. Specs replace the stored spec so
+// configuration changes are picked up; specs for processes the daemon does
+// not know yet are simply started.
+func (s *Server) restart(names []string, specs []Spec) ([]string, error) {
+	bySpec := map[string]Spec{}
+	for _, spec := range specs {
+		bySpec[spec.Name] = spec
+		names = append(names, spec.Name)
+	}
+	names = unique(names)
+
+	if len(names) == 0 {
+		for _, status := range s.status(nil) {
+			names = append(names, status.Name)
+		}
+	}
+
+	for _, name := range names {
+		s.mu.Lock()
+		existing, known := s.procs[name]
+		s.mu.Unlock()
+
+		spec, hasSpec := bySpec[name]
+		switch {
+		case !known && !hasSpec:
+			return names, fmt.Errorf("'%s' is not managed by the daemon", name)
+		case !hasSpec:
+			spec = existing.spec
+		}
+
+		restarts := 0
+		if known {
+			s.terminate(existing)
+			restarts = existing.restarts + 1
+		}
+
+		s.mu.Lock()
+		proc, err := s.launch(spec)
+		if err != nil {
+			s.mu.Unlock()
+			return names, err
+		}
+		proc.restarts = restarts
+		s.procs[name] = proc
+		s.mu.Unlock()
+	}
+
+	return names, nil
+}
+
+// terminate stops a running process and waits for it to be reaped
+func (s *Server) terminate(proc *process) {
+	s.mu.Lock()
+	running := proc.state == StateRunning
+	if running || proc.state == StateRestarting {
+		proc.state = StateStopped
+	}
+	s.mu.Unlock()
+
+	if running {
+		log.Printf("stopping %s (pid %d)", proc.spec.Name, proc.cmd.Process.Pid)
+		grace := proc.spec.Project.StopTimeout.Std()
+		if grace <= 0 {
+			grace = runner.StopTimeout
+		}
+		runner.Stop(proc.cmd, proc.waitErr, nil, grace)
+	}
+	<-proc.done
+}
+
+func (s *Server) stopAll() {
+	s.stop(nil)
+}
+
+// lookup returns the named processes, or every process when names is empty
+func (s *Server) lookup(names []string) ([]*process, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var procs []*process
+	if len(names) == 0 {
+		for _, proc := range s.procs {
+			procs = append(procs, proc)
+		}
+		return procs, nil
+	}
+
+	for _, name := range names {
+		proc, ok := s.procs[name]
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not managed by the daemon", name)
+		}
+		procs = append(procs, proc)
+	}
+	return procs, nil
+}
+
+// status reports the named processes, or all of them when names is empty,
+// sorted by name
+func (s *Server) status(names []string) []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var statuses []Status
+	for name, proc := range s.procs {
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		task, _ := proc.spec.Project.GetTask(proc.spec.Task)
+		statuses = append(statuses, Status{
+			Name:      name,
+			Project:   proc.spec.Project.Name,
+			Command:   task.Command,
+			State:     proc.state,
+			PID:       proc.cmd.Process.Pid,
+			StartedAt: proc.startedAt,
+			EndedAt:   proc.endedAt,
+			ExitCode:  proc.exitCode,
+			Restarts:  proc.restarts,
+			LogFile:   proc.logFile,
+			Overrides: proc.spec.Overrides,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+func unique(names []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
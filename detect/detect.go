@@ -0,0 +1,103 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Candidate is a command that could run a project, found by looking at the
+// files in its directory
+type Candidate struct {
+	Command string
+	// Source says where the command was found, e.g. "package.json script 'dev'"
+	Source string
+	// Server reports whether the command looks like it starts a dev server,
+	// as opposed to e.g. a test or build script
+	Server bool
+}
+
+// detectors are tried in order; earlier ones win when several find a server
+// command in the same directory
+var detectors = []func(dir string) []Candidate{
+	procfile,
+	packageJSON,
+	goModule,
+	cargo,
+	django,
+	rails,
+	makefile,
+	compose,
+}
+
+// Detect returns the commands that could run the project in dir, dev server
+// commands first and the most likely one of them first of all. It returns
+// nil when dir does not look like a project.
+func Detect(dir string) []Candidate {
+	var candidates []Candidate
+	seen := map[string]bool{}
+	for _, detector := range detectors {
+		for _, candidate := range detector(dir) {
+			if !seen[candidate.Command] {
+				seen[candidate.Command] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Server && !candidates[j].Server
+	})
+	return candidates
+}
+
+// Best returns the most likely dev server command for dir, or false when
+// none was found
+func Best(dir string) (Candidate, bool) {
+	candidates := Detect(dir)
+	if len(candidates) == 0 || !candidates[0].Server {
+		return Candidate{}, false
+	}
+	return candidates[0], true
+}
+
+// SuggestName proposes a project name for dir based on its base name, adding
+// a number when taken reports that the name is already used
+func SuggestName(dir string, taken func(name string) bool) string {
+	base := strings.ToLower(filepath.Base(dir))
+	base = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', ':', '/', '\\':
+			return '-'
+		}
+		return r
+	}, base)
+	// @ would read as a group in dev run
+	base = strings.TrimLeft(base, "@.-")
+	if base == "" {
+		base = "project"
+	}
+
+	name := base
+	for i := 2; taken(name); i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	return name
+}
+
+// exists reports whether dir contains a file called name
+func exists(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+// firstExisting returns the first of names that exists in dir, or ""
+func firstExisting(dir string, names ...string) string {
+	for _, name := range names {
+		if exists(dir, name) {
+			return name
+		}
+	}
+	return ""
+}
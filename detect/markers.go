@@ -0,0 +1,261 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// serverScripts are package.json scripts that usually start a dev server,
+// most likely first
+var serverScripts = []string{"dev", "start", "serve", "develop"}
+
+// serverTargets are Makefile targets that usually start a dev server
+var serverTargets = []string{"dev", "run", "serve", "server", "start", "up", "watch"}
+
+// packageJSON offers the scripts of a Node.js project, run with the package
+// manager its lockfile belongs to
+func packageJSON(dir string) []Candidate {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Scripts        map[string]string `json:"scripts"`
+		PackageManager string            `json:"packageManager"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+
+	scripts := make([]string, 0, len(pkg.Scripts))
+	for script := range pkg.Scripts {
+		scripts = append(scripts, script)
+	}
+	sort.SliceStable(scripts, func(i, j int) bool {
+		return scriptRank(scripts[i]) < scriptRank(scripts[j]) ||
+			scriptRank(scripts[i]) == scriptRank(scripts[j]) && scripts[i] < scripts[j]
+	})
+
+	run := nodeRunner(dir, pkg.PackageManager)
+	var candidates []Candidate
+	for _, script := range scripts {
+		candidates = append(candidates, Candidate{
+			Command: run + script,
+			Source:  fmt.Sprintf("package.json script '%s'", script),
+			Server:  scriptRank(script) < len(serverScripts),
+		})
+	}
+	return candidates
+}
+
+// scriptRank orders server scripts by likelihood, before all other scripts
+func scriptRank(script string) int {
+	for i, name := range serverScripts {
+		if script == name {
+			return i
+		}
+	}
+	return len(serverScripts)
+}
+
+// nodeRunner returns the command prefix that runs a package.json script,
+// chosen by lockfile and falling back to the packageManager field
+func nodeRunner(dir, packageManager string) string {
+	manager := "npm"
+	switch {
+	case exists(dir, "pnpm-lock.yaml"):
+		manager = "pnpm"
+	case exists(dir, "yarn.lock"):
+		manager = "yarn"
+	case exists(dir, "bun.lockb"), exists(dir, "bun.lock"):
+		manager = "bun"
+	case exists(dir, "package-lock.json"):
+		manager = "npm"
+	case packageManager != "":
+		manager = strings.SplitN(packageManager, "@", 2)[0]
+	}
+
+	switch manager {
+	case "pnpm", "yarn":
+		return manager + " "
+	case "bun":
+		return "bun run "
+	}
+	return "npm run "
+}
+
+// goModule offers go run for each main package at the root of a Go module
+// or under its cmd directory
+func goModule(dir string) []Candidate {
+	if !exists(dir, "go.mod") {
+		return nil
+	}
+
+	var candidates []Candidate
+	if isMainPackage(dir) {
+		candidates = append(candidates, Candidate{Command: "go run .", Source: "go.mod main package", Server: true})
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "cmd"))
+	for _, entry := range entries {
+		if entry.IsDir() && isMainPackage(filepath.Join(dir, "cmd", entry.Name())) {
+			candidates = append(candidates, Candidate{
+				Command: "go run ./cmd/" + entry.Name(),
+				Source:  fmt.Sprintf("go.mod main package cmd/%s", entry.Name()),
+				Server:  true,
+			})
+		}
+	}
+	return candidates
+}
+
+// isMainPackage reports whether dir holds Go files of package main
+func isMainPackage(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err == nil && f.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+func cargo(dir string) []Candidate {
+	if !exists(dir, "Cargo.toml") {
+		return nil
+	}
+	return []Candidate{{Command: "cargo run", Source: "Cargo.toml", Server: true}}
+}
+
+func django(dir string) []Candidate {
+	if !exists(dir, "manage.py") {
+		return nil
+	}
+	return []Candidate{{Command: "python manage.py runserver", Source: "manage.py", Server: true}}
+}
+
+var railsGem = regexp.MustCompile(`(?m)^\s*gem\s+['"]rails['"]`)
+
+// rails offers bin/dev where the app has one, as it also starts asset
+// watchers, and the Rails server otherwise
+func rails(dir string) []Candidate {
+	data, err := os.ReadFile(filepath.Join(dir, "Gemfile"))
+	if err != nil || !railsGem.Match(data) {
+		return nil
+	}
+
+	var candidates []Candidate
+	if exists(dir, filepath.Join("bin", "dev")) {
+		candidates = append(candidates, Candidate{Command: "bin/dev", Source: "Rails bin/dev", Server: true})
+	}
+	if exists(dir, filepath.Join("bin", "rails")) {
+		candidates = append(candidates, Candidate{Command: "bin/rails server", Source: "Rails", Server: true})
+	} else {
+		candidates = append(candidates, Candidate{Command: "bundle exec rails server", Source: "Rails", Server: true})
+	}
+	return candidates
+}
+
+// makeTarget matches a rule line; variable assignments such as FOO := bar
+// are excluded by requiring the colon not to be followed by =
+var makeTarget = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./ -]*?)\s*::?(?:[^=]|$)`)
+
+// makefile offers make for each explicit target
+func makefile(dir string) []Candidate {
+	name := firstExisting(dir, "GNUmakefile", "makefile", "Makefile")
+	if name == "" {
+		return nil
+	}
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var servers, others []Candidate
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		match := makeTarget.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		for _, target := range strings.Fields(match[1]) {
+			if seen[target] || strings.ContainsAny(target, "%$/") {
+				continue
+			}
+			seen[target] = true
+			candidate := Candidate{
+				Command: "make " + target,
+				Source:  fmt.Sprintf("%s target '%s'", name, target),
+				Server:  containsString(serverTargets, target),
+			}
+			if candidate.Server {
+				servers = append(servers, candidate)
+			} else {
+				others = append(others, candidate)
+			}
+		}
+	}
+	return append(servers, others...)
+}
+
+var procfileLine = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*(.+)$`)
+
+// procfile offers the processes of a Procfile; web is the dev server
+func procfile(dir string) []Candidate {
+	f, err := os.Open(filepath.Join(dir, "Procfile"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var web, others []Candidate
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		match := procfileLine.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+		candidate := Candidate{
+			Command: strings.TrimSpace(match[2]),
+			Source:  fmt.Sprintf("Procfile process '%s'", match[1]),
+			Server:  match[1] == "web",
+		}
+		if candidate.Server {
+			web = append(web, candidate)
+		} else {
+			others = append(others, candidate)
+		}
+	}
+	return append(web, others...)
+}
+
+func compose(dir string) []Candidate {
+	name := firstExisting(dir, "compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml")
+	if name == "" {
+		return nil
+	}
+	return []Candidate{{Command: "docker compose up", Source: name, Server: true}}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"dev-util/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CycleError reports projects that depend on each other in a circle
+type CycleError struct {
+	// Cycle lists the projects along the cycle, starting and ending with
+	// the same project
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	return "dependency cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+// Graph is the dependency graph of a set of projects
+type Graph struct {
+	projects map[string]*models.Project
+}
+
+// New builds the dependency graph of projects
+func New(projects []models.Project) *Graph {
+	g := &Graph{projects: map[string]*models.Project{}}
+	for i := range projects {
+		g.projects[projects[i].Name] = &projects[i]
+	}
+	return g
+}
+
+// Order returns roots and everything they depend on, directly or not, with
+// every project listed after its dependencies. It fails on unknown
+// dependencies and on cycles.
+func (g *Graph) Order(roots []string) ([]string, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var order, path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for i, p := range path {
+				if p == name {
+					start = i
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return &CycleError{Cycle: cycle}
+		}
+
+		project, ok := g.projects[name]
+		if !ok {
+			if len(path) == 0 {
+				return fmt.Errorf("project '%s' not found", name)
+			}
+			return fmt.Errorf("'%s' depends on '%s', which is not a registered project", path[len(path)-1], name)
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range project.DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, root := range roots {
+		if err := visit(root); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Check returns an error when the projects' dependencies contain a cycle or
+// refer to unknown projects
+func (g *Graph) Check() error {
+	_, err := g.Order(g.names())
+	return err
+}
+
+// Roots returns the projects no other project depends on, sorted by name
+func (g *Graph) Roots() []string {
+	dependedOn := map[string]bool{}
+	for _, project := range g.projects {
+		for _, dep := range project.DependsOn {
+			dependedOn[dep] = true
+		}
+	}
+
+	var roots []string
+	for _, name := range g.names() {
+		if !dependedOn[name] {
+			roots = append(roots, name)
+		}
+	}
+	return roots
+}
+
+// Tree renders the dependencies of roots as an indented tree. Projects
+// reached again further down are marked instead of being expanded twice.
+func (g *Graph) Tree(roots []string) string {
+	var b strings.Builder
+	for _, root := range roots {
+		b.WriteString(root + "\n")
+		g.writeTree(&b, root, "", map[string]bool{root: true})
+	}
+	return b.String()
+}
+
+func (g *Graph) writeTree(b *strings.Builder, name, indent string, ancestors map[string]bool) {
+	project, ok := g.projects[name]
+	if !ok {
+		return
+	}
+
+	for i, dep := range project.DependsOn {
+		branch, next := "├── ", "│   "
+		if i == len(project.DependsOn)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		label := dep
+		_, known := g.projects[dep]
+		switch {
+		case !known:
+			label += " (not registered)"
+		case ancestors[dep]:
+			label += " (cycle)"
+		}
+		b.WriteString(indent + branch + label + "\n")
+
+		if known && !ancestors[dep] {
+			ancestors[dep] = true
+			g.writeTree(b, dep, indent+next, ancestors)
+			delete(ancestors, dep)
+		}
+	}
+}
+
+// DOT renders the dependencies of roots, or of every project when roots is
+// empty, in Graphviz DOT format. Edges point from a project to the projects
+// it depends on.
+func (g *Graph) DOT(roots []string) string {
+	names := roots
+	if len(names) == 0 {
+		names = g.names()
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph dev {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	seen := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		fmt.Fprintf(&b, "  %q;\n", name)
+
+		project, ok := g.projects[name]
+		if !ok {
+			return
+		}
+		for _, dep := range project.DependsOn {
+			fmt.Fprintf(&b, "  %q -> %q;\n", name, dep)
+		}
+		for _, dep := range project.DependsOn {
+			visit(dep)
+		}
+	}
+	for _, name := range names {
+		visit(name)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *Graph) names() []string {
+	names := make([]string, 0, len(g.projects))
+	for name := range g.projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,265 @@
+package logs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is one line read back from a log file
+type Entry struct {
+	Time   time.Time
+	Name   string
+	Stream string
+	Text   string
+}
+
+// IsStart reports whether the entry marks the start of a run, as recorded by
+// Writer.MarkStart
+func (e Entry) IsStart() bool {
+	return e.Stream == Dev && strings.HasPrefix(e.Text, startNote)
+}
+
+// Filter selects entries when reading logs
+type Filter struct {
+	// Since drops entries older than this time when non-zero
+	Since time.Time
+	// Grep keeps only entries whose text matches when non-nil
+	Grep *regexp.Regexp
+}
+
+// Match reports whether e passes the filter
+func (f Filter) Match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Grep != nil && !f.Grep.MatchString(e.Text) {
+		return false
+	}
+	return true
+}
+
+// Read returns the matching entries of name's logs in dir, including rotated
+// files, oldest first
+func Read(dir, name string, filter Filter) ([]Entry, error) {
+	path := FilePath(dir, name)
+
+	var files []string
+	for i := DefaultMaxFiles; i >= 1; i-- {
+		files = append(files, fmt.Sprintf("%s.%d", path, i))
+	}
+	files = append(files, path)
+
+	var entries []Entry
+	found := false
+	for _, file := range files {
+		f, err := os.Open(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		entries, err = scan(f, name, filter, entries)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no logs found for '%s'", name)
+	}
+	return entries, nil
+}
+
+// Merge combines entries from several logs into a single list ordered by
+// time. Entries with equal timestamps keep their original order.
+func Merge(lists ...[]Entry) []Entry {
+	var merged []Entry
+	for _, list := range lists {
+		merged = append(merged, list...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+	return merged
+}
+
+// Tail returns the last n entries, or all of them when n < 0
+func Tail(entries []Entry, n int) []Entry {
+	if n < 0 || n >= len(entries) {
+		return entries
+	}
+	return entries[len(entries)-n:]
+}
+
+// maxLineLength bounds how much of a single log line is read back; the rest
+// of a longer line, such as a dumped minified bundle, is skipped
+const maxLineLength = 1024 * 1024
+
+// truncatedMark is appended to lines cut at maxLineLength
+const truncatedMark = " [truncated]"
+
+// scan parses log lines from r and appends matching entries
+func scan(r io.Reader, name string, filter Filter, entries []Entry) ([]Entry, error) {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := readLine(reader)
+		if line != "" {
+			entry, ok := parseLine(line, name)
+			if ok && filter.Match(entry) {
+				entries = append(entries, entry)
+			}
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+	}
+}
+
+// readLine returns the next line without its line ending, cut to
+// maxLineLength. At the end of r it returns io.EOF along with any final
+// line that lacks a newline.
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	truncated := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !truncated {
+			if room := maxLineLength - len(line); len(chunk) > room {
+				line = append(line, chunk[:room]...)
+				truncated = true
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		if truncated {
+			return strings.ToValidUTF8(string(line), "") + truncatedMark, err
+		}
+		text := strings.TrimSuffix(string(line), "\n")
+		return strings.TrimSuffix(text, "\r"), err
+	}
+}
+
+// parseLine splits a stored line into its timestamp, stream and text
+func parseLine(line, name string) (Entry, bool) {
+	stamp, rest, ok := strings.Cut(line, " ")
+	if !ok {
+		return Entry{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, stamp)
+	if err != nil {
+		return Entry{}, false
+	}
+	stream, text, _ := strings.Cut(rest, " ")
+	return Entry{Time: t, Name: name, Stream: stream, Text: text}, true
+}
+
+// Follower reads entries appended to a log file after it was opened. It
+// notices when the file is rotated and continues with the new one.
+type Follower struct {
+	path   string
+	name   string
+	filter Filter
+	file   *os.File
+	offset int64
+	rest   string
+}
+
+// Follow returns a Follower positioned at the current end of name's log.
+// The file does not have to exist yet.
+func Follow(dir, name string, filter Filter) *Follower {
+	f := &Follower{path: FilePath(dir, name), name: name, filter: filter}
+	if info, err := os.Stat(f.path); err == nil {
+		f.offset = info.Size()
+	}
+	return f
+}
+
+// Poll returns the matching entries written since the previous call
+func (f *Follower) Poll() ([]Entry, error) {
+	info, err := os.Stat(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A smaller file means it was rotated or truncated; start over
+	if info.Size() < f.offset || (f.file != nil && !sameFile(f.file, info)) {
+		f.close()
+		f.offset = 0
+		f.rest = ""
+	}
+	if info.Size() == f.offset {
+		return nil, nil
+	}
+
+	if f.file == nil {
+		if f.file, err = os.Open(f.path); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := f.file.Seek(f.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(f.file)
+	if err != nil {
+		return nil, err
+	}
+	f.offset += int64(len(data))
+
+	// Keep a trailing partial line for the next poll
+	text := f.rest + string(data)
+	lastNewline := strings.LastIndexByte(text, '\n')
+	if lastNewline < 0 {
+		f.rest = text
+		return nil, nil
+	}
+	f.rest = text[lastNewline+1:]
+
+	var entries []Entry
+	for _, line := range strings.Split(text[:lastNewline], "\n") {
+		entry, ok := parseLine(line, f.name)
+		if ok && f.filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// Close releases the followed file
+func (f *Follower) Close() {
+	f.close()
+}
+
+func (f *Follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+func sameFile(f *os.File, info os.FileInfo) bool {
+	current, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return os.SameFile(current, info)
+}
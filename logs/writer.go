@@ -0,0 +1,246 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the size at which a log file is rotated
+	DefaultMaxSize = 10 * 1024 * 1024
+
+	// DefaultMaxFiles is how many rotated files are kept besides the
+	// active one
+	DefaultMaxFiles = 3
+)
+
+// Stream names recorded with every line
+const (
+	Stdout = "out"
+	Stderr = "err"
+	// Dev marks lines written by dev itself, such as restart notices
+	Dev = "dev"
+)
+
+// FilePath returns the active log file for name inside dir. Characters that
+// are awkward in file names, such as the colon in "api:test", are replaced.
+func FilePath(dir, name string) string {
+	safe := strings.NewReplacer("/", "_", "\\", "_", ":", ".").Replace(name)
+	return filepath.Join(dir, safe+".log")
+}
+
+// Writer appends timestamped lines to a size-rotated log file. Each line is
+// stored as "<RFC3339 timestamp> <stream> <text>" so logs of several
+// projects can be merged by time later.
+type Writer struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// teeErr is the first error the writers returned by Tee ran into
+	teeErr  error
+	errOnce sync.Once
+}
+
+// Open opens (creating if necessary) the log file for name in dir
+func Open(dir, name string) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	w := &Writer{
+		path:     FilePath(dir, name),
+		maxSize:  DefaultMaxSize,
+		maxFiles: DefaultMaxFiles,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Path returns the active log file
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Stream returns an io.Writer that records everything written to it as lines
+// of the given stream. Call Flush on it to record a trailing partial line.
+func (w *Writer) Stream(stream string) *StreamWriter {
+	return &StreamWriter{log: w, stream: stream}
+}
+
+// startNote begins the Dev line recorded whenever a command is started
+const startNote = "started: "
+
+// MarkStart records that command is being started, so that readers can tell
+// the output of the current run from earlier ones
+func (w *Writer) MarkStart(command string) error {
+	return w.writeLine(Dev, []byte(startNote+command))
+}
+
+// Notef records a line of the Dev stream
+func (w *Writer) Notef(format string, args ...interface{}) error {
+	return w.writeLine(Dev, []byte(fmt.Sprintf(format, args...)))
+}
+
+// Close closes the log file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// writeLine records one line, rotating the file first if it would grow past
+// maxSize
+func (w *Writer) writeLine(stream string, text []byte) error {
+	line := make([]byte, 0, len(text)+48)
+	line = time.Now().UTC().AppendFormat(line, time.RFC3339Nano)
+	line = append(line, ' ')
+	line = append(line, stream...)
+	line = append(line, ' ')
+	line = append(line, text...)
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.size > 0 && w.size+int64(len(line)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	return err
+}
+
+// rotate shifts name.log.N to name.log.N+1, dropping the oldest, moves the
+// active file to name.log.1 and starts a new one
+func (w *Writer) rotate() error {
+	w.file.Close()
+
+	os.Remove(fmt.Sprintf("%s.%d", w.path, w.maxFiles))
+	for i := w.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+	}
+	if err := os.Rename(w.path, w.path+".1"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	return w.open()
+}
+
+// StreamWriter splits written data into lines for a Writer
+type StreamWriter struct {
+	log    *Writer
+	stream string
+	buf    []byte
+}
+
+// Write implements io.Writer
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	var firstErr error
+	s.buf = append(s.buf, p...)
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := bytes.TrimSuffix(s.buf[:i], []byte("\r"))
+		// A line that cannot be written is dropped rather than retried
+		if err := s.log.writeLine(s.stream, line); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		s.buf = s.buf[i+1:]
+	}
+	return len(p), firstErr
+}
+
+// Flush records any buffered partial line
+func (s *StreamWriter) Flush() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	line := s.buf
+	s.buf = nil
+	return s.log.writeLine(s.stream, line)
+}
+
+// Tee returns writers for a command's stdout and stderr that copy everything
+// to the given destinations and to the log. flush must be called once the
+// command has exited.
+//
+// Logging is best effort: when writing to the log fails, e.g. because the
+// disk is full, the output still reaches the destinations. The first such
+// error is reported once on stderr and kept for Err.
+func (w *Writer) Tee(stdout, stderr io.Writer) (io.Writer, io.Writer, func()) {
+	report := func(err error) {
+		w.errOnce.Do(func() {
+			w.mu.Lock()
+			w.teeErr = err
+			w.mu.Unlock()
+			fmt.Fprintf(stderr, "⚠️  Failed to write log %s, output may be missing from it: %v\n", w.path, err)
+		})
+	}
+
+	out := w.Stream(Stdout)
+	errw := w.Stream(Stderr)
+	flush := func() {
+		if err := out.Flush(); err != nil {
+			report(err)
+		}
+		if err := errw.Flush(); err != nil {
+			report(err)
+		}
+	}
+	return io.MultiWriter(stdout, bestEffort{out, report}),
+		io.MultiWriter(stderr, bestEffort{errw, report}), flush
+}
+
+// Err returns the first error the writers returned by Tee ran into, if any
+func (w *Writer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.teeErr
+}
+
+// bestEffort passes writes on to a log stream, reporting errors instead of
+// returning them so that they never interrupt the command's output
+type bestEffort struct {
+	w      io.Writer
+	report func(error)
+}
+
+func (b bestEffort) Write(p []byte) (int, error) {
+	if _, err := b.w.Write(p); err != nil {
+		b.report(err)
+	}
+	return len(p), nil
+}
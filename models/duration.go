@@ -0,0 +1,47 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration stored in JSON as a human readable string
+// such as "10s" or "1m30s"
+type Duration time.Duration
+
+// Std returns d as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// String formats d like time.Duration
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON accepts duration strings as well as plain numbers of
+// nanoseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration '%s': %v", s, err)
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = Duration(n)
+	return nil
+}
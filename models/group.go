@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Group is a named set of projects, e.g. the services making up one product.
+// A project can belong to several groups.
+type Group struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Projects    []string  `json:"projects"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Has reports whether the project is a member of the group
+func (g *Group) Has(project string) bool {
+	for _, name := range g.Projects {
+		if name == project {
+			return true
+		}
+	}
+	return false
+}
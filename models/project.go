@@ -1,19 +1,245 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CurrentVersion is the schema version of ProjectStore written by this build.
+// Bump it together with a new migration in the storage package whenever the
+// on-disk layout changes, including when a field is added to Project or
+// ProjectStore: older builds drop fields they do not know when saving, and
+// the bump makes them refuse the file instead. A migration that only adds a
+// field has nothing to convert and may do nothing.
+const CurrentVersion = 3
+
+// DefaultTask is the name under which a project's Command can be run as a
+// task
+const DefaultTask = "default"
+
+// Shell settings control how a project's commands are executed
+const (
+	// ShellAuto runs commands through sh only when they use shell syntax
+	ShellAuto = ""
+	// ShellNone always splits the command into words and runs it directly
+	ShellNone = "none"
+	ShellSh   = "sh"
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+)
+
+// Shells lists the accepted Shell values, excluding the empty default
+var Shells = []string{ShellNone, ShellSh, ShellBash, ShellZsh}
+
+// ValidateShell returns an error when shell is not a supported setting
+func ValidateShell(shell string) error {
+	if shell == ShellAuto {
+		return nil
+	}
+	for _, s := range Shells {
+		if s == shell {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported shell '%s' (supported: none, sh, bash, zsh)", shell)
+}
+
+// Restart policies control whether a project's command is started again
+// after it exits
+const (
+	// RestartNever leaves the command stopped; it is the default
+	RestartNever = "never"
+	// RestartOnFailure restarts the command when it exits with a non-zero
+	// status
+	RestartOnFailure = "on-failure"
+	// RestartAlways restarts the command whenever it exits
+	RestartAlways = "always"
+)
+
+// RestartPolicies lists the accepted Restart values
+var RestartPolicies = []string{RestartNever, RestartOnFailure, RestartAlways}
+
+// DefaultMaxRestarts caps consecutive restarts when a project does not set
+// MaxRestarts
+const DefaultMaxRestarts = 5
+
+// ValidateRestart returns an error when policy is not a supported restart
+// policy
+func ValidateRestart(policy string) error {
+	if policy == "" {
+		return nil
+	}
+	for _, p := range RestartPolicies {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported restart policy '%s' (supported: never, on-failure, always)", policy)
+}
+
+// ValidatePort returns an error when port is not a usable TCP port number
+func ValidatePort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %d (must be between 1 and 65535)", port)
+	}
+	return nil
+}
+
+// ValidateTag returns an error when tag cannot be used as a project tag.
+// Tags are single words; a leading "!" is reserved for excluding a tag in
+// filters.
+func ValidateTag(tag string) error {
+	switch {
+	case tag == "":
+		return fmt.Errorf("tag cannot be empty")
+	case strings.HasPrefix(tag, "!"):
+		return fmt.Errorf("invalid tag '%s' (must not start with '!')", tag)
+	case strings.ContainsAny(tag, ", \t"):
+		return fmt.Errorf("invalid tag '%s' (must not contain commas or spaces)", tag)
+	}
+	return nil
+}
 
 // Project represents a development project configuration
 type Project struct {
-	Name        string    `json:"name"`
-	Path        string    `json:"path"`
-	Command     string    `json:"command"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	Name        string            `json:"name"`
+	Path        string            `json:"path"`
+	Command     string            `json:"command"`
+	Description string            `json:"description,omitempty"`
+	Tasks       map[string]Task   `json:"tasks,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	EnvFiles    []string          `json:"env_files,omitempty"`
+	Shell       string            `json:"shell,omitempty"`
+	StopTimeout Duration          `json:"stop_timeout,omitempty"`
+	Restart     string            `json:"restart,omitempty"`
+	MaxRestarts int               `json:"max_restarts,omitempty"`
+	Watch       *WatchConfig      `json:"watch,omitempty"`
+	Ports       []int             `json:"ports,omitempty"`
+	Ready       *ReadyCheck       `json:"ready,omitempty"`
+	DependsOn   []string          `json:"depends_on,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at,omitempty"`
+}
+
+// WatchConfig controls restarting a project's command when its files change
+type WatchConfig struct {
+	// Enabled makes dev run watch the project without --watch
+	Enabled bool `json:"enabled,omitempty"`
+	// Include and Exclude are glob patterns relative to the project
+	// directory; "**" matches any number of directories
+	Include  []string `json:"include,omitempty"`
+	Exclude  []string `json:"exclude,omitempty"`
+	Debounce Duration `json:"debounce,omitempty"`
+	// Gitignore controls whether files ignored by git are skipped; unset
+	// means true
+	Gitignore *bool `json:"gitignore,omitempty"`
+}
+
+// RespectsGitignore reports whether files ignored by git are skipped
+func (w *WatchConfig) RespectsGitignore() bool {
+	return w == nil || w.Gitignore == nil || *w.Gitignore
+}
+
+// ReadyCheck is a readiness probe telling when a started project is ready to
+// serve. Exactly one of TCP, HTTP, Log and Command is set.
+type ReadyCheck struct {
+	// TCP is an address that accepts connections once ready, e.g.
+	// "localhost:5432" or just a port
+	TCP string `json:"tcp,omitempty"`
+	// HTTP is a URL that answers a GET with a 2xx status once ready
+	HTTP string `json:"http,omitempty"`
+	// Log is a regular expression matching an output line printed once
+	// ready
+	Log string `json:"log,omitempty"`
+	// Command is run in the project directory and exits 0 once ready
+	Command string `json:"command,omitempty"`
+	// Timeout is how long to wait in total and Interval how long to wait
+	// between attempts; zero means the defaults
+	Timeout  Duration `json:"timeout,omitempty"`
+	Interval Duration `json:"interval,omitempty"`
+}
+
+// Kind names the probe type: "tcp", "http", "log" or "command"
+func (r *ReadyCheck) Kind() string {
+	switch {
+	case r.TCP != "":
+		return "tcp"
+	case r.HTTP != "":
+		return "http"
+	case r.Log != "":
+		return "log"
+	case r.Command != "":
+		return "command"
+	}
+	return ""
+}
+
+// Target returns the address, URL, pattern or command being probed
+func (r *ReadyCheck) Target() string {
+	return r.TCP + r.HTTP + r.Log + r.Command
+}
+
+// Validate returns an error unless exactly one probe type is set
+func (r *ReadyCheck) Validate() error {
+	set := 0
+	for _, value := range []string{r.TCP, r.HTTP, r.Log, r.Command} {
+		if value != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("a readiness check needs exactly one of tcp, http, log or command")
+	}
+	return nil
+}
+
+// Task is a named command that can be run for a project, such as "test" or
+// "lint". The project's Command is the default task.
+type Task struct {
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+}
+
+// GetTask returns the task called name. An empty name or DefaultTask returns
+// the project's Command unless a task of that name was defined explicitly.
+func (p *Project) GetTask(name string) (Task, error) {
+	if task, ok := p.Tasks[name]; ok && name != "" {
+		return task, nil
+	}
+	if name == "" || name == DefaultTask {
+		return Task{Command: p.Command}, nil
+	}
+	return Task{}, fmt.Errorf("project '%s' has no task '%s'", p.Name, name)
+}
+
+// TaskNames returns the names of the project's tasks in sorted order
+func (p *Project) TaskNames() []string {
+	names := make([]string, 0, len(p.Tasks))
+	for name := range p.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasTag reports whether the project carries tag
+func (p *Project) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // ProjectStore manages the collection of projects
 type ProjectStore struct {
+	Version  int       `json:"version"`
 	Projects []Project `json:"projects"`
+	Groups   []Group   `json:"groups"`
 }
 
 // AddProject adds a new project to the store
@@ -36,11 +262,77 @@ func (ps *ProjectStore) ListProjects() []Project {
 	return ps.Projects
 }
 
-// RemoveProject removes a project by name
+// UpdateProject replaces the project called name with project. The
+// replacement may carry a different name, which renames the project in every
+// group it belongs to.
+func (ps *ProjectStore) UpdateProject(name string, project Project) bool {
+	for i := range ps.Projects {
+		if ps.Projects[i].Name == name {
+			ps.Projects[i] = project
+			if project.Name != name {
+				ps.renameMember(name, project.Name)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveProject removes a project by name, also from every group
 func (ps *ProjectStore) RemoveProject(name string) bool {
 	for i, project := range ps.Projects {
 		if project.Name == name {
 			ps.Projects = append(ps.Projects[:i], ps.Projects[i+1:]...)
+			ps.renameMember(name, "")
+			return true
+		}
+	}
+	return false
+}
+
+// renameMember replaces a project in every group's members, dropping it when
+// newName is empty
+func (ps *ProjectStore) renameMember(oldName, newName string) {
+	for i := range ps.Groups {
+		var members []string
+		for _, member := range ps.Groups[i].Projects {
+			switch {
+			case member != oldName:
+				members = append(members, member)
+			case newName != "":
+				members = append(members, newName)
+			}
+		}
+		ps.Groups[i].Projects = members
+	}
+}
+
+// GetGroup retrieves a group by name
+func (ps *ProjectStore) GetGroup(name string) (*Group, bool) {
+	for _, group := range ps.Groups {
+		if group.Name == name {
+			return &group, true
+		}
+	}
+	return nil, false
+}
+
+// SaveGroup adds the group, or replaces the group of the same name
+func (ps *ProjectStore) SaveGroup(group Group) {
+	for i := range ps.Groups {
+		if ps.Groups[i].Name == group.Name {
+			ps.Groups[i] = group
+			return
+		}
+	}
+	ps.Groups = append(ps.Groups, group)
+}
+
+// RemoveGroup removes a group by name
+func (ps *ProjectStore) RemoveGroup(name string) bool {
+	for i, group := range ps.Groups {
+		if group.Name == name {
+			ps.Groups = append(ps.Groups[:i], ps.Groups[i+1:]...)
 			return true
 		}
 	}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Run records a single invocation of a project's dev server
+type Run struct {
+	Project   string        `json:"project"`
+	StartedAt time.Time     `json:"started_at"`
+	EndedAt   time.Time     `json:"ended_at"`
+	ExitCode  int           `json:"exit_code"`
+	Duration  time.Duration `json:"duration"`
+}
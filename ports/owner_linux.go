@@ -0,0 +1,96 @@
+//go:build linux
+
+package ports
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tcpListen is the state of a listening socket in /proc/net/tcp
+const tcpListen = "0A"
+
+// findOwner looks up the socket listening on port in /proc/net/tcp and
+// /proc/net/tcp6 and searches the open file descriptors of every process
+// for it. Processes of other users cannot be inspected without privileges,
+// in which case nil is returned.
+func findOwner(port int) (*Owner, error) {
+	inodes := map[string]bool{}
+	for _, table := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		if err := listeningInodes(table, port, inodes); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			if inodes[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] {
+				return processInfo(pid), nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// listeningInodes adds the inodes of sockets listening on port found in a
+// /proc/net/tcp style table
+func listeningInodes(table string, port int, inodes map[string]bool) error {
+	f, err := os.Open(table)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListen {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if p, err := strconv.ParseInt(hexPort, 16, 32); err == nil && int(p) == port && fields[9] != "0" {
+			inodes[fields[9]] = true
+		}
+	}
+	return scanner.Err()
+}
+
+// processInfo reads the name and command line of a process
+func processInfo(pid int) *Owner {
+	owner := &Owner{PID: pid}
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		owner.Name = strings.TrimSpace(string(comm))
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		owner.Command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	return owner
+}
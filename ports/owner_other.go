@@ -0,0 +1,9 @@
+//go:build !linux
+
+package ports
+
+// findOwner is not implemented outside Linux; the owner of a port in use is
+// reported as unknown
+func findOwner(port int) (*Owner, error) {
+	return nil, nil
+}
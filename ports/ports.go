@@ -0,0 +1,87 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Owner is a process listening on a port
+type Owner struct {
+	PID int
+	// Name is the process's executable name, e.g. "node"
+	Name string
+	// Command is the full command line, when it can be read
+	Command string
+}
+
+// String describes the owner for messages, e.g. "node (pid 1234)"
+func (o *Owner) String() string {
+	if o.Name == "" {
+		return fmt.Sprintf("pid %d", o.PID)
+	}
+	return fmt.Sprintf("%s (pid %d)", o.Name, o.PID)
+}
+
+// Status is the result of checking a port
+type Status struct {
+	Port  int
+	InUse bool
+	// Owner is the listening process; nil when the port is free or its
+	// owner could not be determined, e.g. because it belongs to another
+	// user or the platform offers no way to find out
+	Owner *Owner
+}
+
+// Check reports whether a TCP port is in use and, where possible, which
+// process is listening on it
+func Check(port int) Status {
+	status := Status{Port: port, InUse: !available(port)}
+	if status.InUse {
+		status.Owner, _ = findOwner(port)
+	}
+	return status
+}
+
+// available reports whether a TCP port can be bound on all interfaces
+func available(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// Kill asks the process owning a port to exit, kills it if it is still
+// alive after grace, and waits up to grace for the port to become free
+func Kill(owner *Owner, port int, grace time.Duration) error {
+	process, err := os.FindProcess(owner.PID)
+	if err != nil {
+		return err
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		// Signals other than kill are not supported everywhere (e.g. Windows)
+		if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("failed to kill %s: %w", owner, err)
+		}
+	}
+
+	deadline := time.Now().Add(grace)
+	killed := false
+	for !available(port) {
+		if time.Now().After(deadline) {
+			if killed {
+				return fmt.Errorf("port %d is still in use after killing %s", port, owner)
+			}
+			process.Kill()
+			killed = true
+			deadline = time.Now().Add(grace)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
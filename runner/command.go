@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"dev-util/models"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// Command builds the exec.Cmd for one of a project's tasks. The command runs
+// in the project directory with the given environment, in a process group
+// of its own.
+//
+// How the command is executed depends on the project's Shell setting: "none"
+// splits it into words and runs it directly, "sh", "bash" and "zsh" hand it
+// to that shell with -c, and the default picks sh only when the command uses
+// shell syntax such as pipes, redirects, globs or variable expansion.
+func Command(project *models.Project, task models.Task, env []string) (*exec.Cmd, error) {
+	command := strings.TrimSpace(task.Command)
+	if command == "" {
+		return nil, fmt.Errorf("project '%s' has an empty command", project.Name)
+	}
+
+	var execCmd *exec.Cmd
+	switch project.Shell {
+	case models.ShellNone:
+		if feature := shellFeature(command); feature != "" {
+			return nil, fmt.Errorf("command '%s' uses %s, which needs a shell; set the project's shell to sh, bash or zsh", command, feature)
+		}
+		var err error
+		if execCmd, env, err = directCommand(command, env); err != nil {
+			return nil, err
+		}
+	case models.ShellSh, models.ShellBash, models.ShellZsh:
+		execCmd = exec.Command(project.Shell, "-c", command)
+	case models.ShellAuto:
+		if shellFeature(command) != "" {
+			execCmd = defaultShellCommand(command)
+		} else {
+			var err error
+			if execCmd, env, err = directCommand(command, env); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown shell '%s' for project '%s' (supported: %s)",
+			project.Shell, project.Name, strings.Join(models.Shells, ", "))
+	}
+
+	execCmd.Dir = project.Path
+	execCmd.Env = env
+	setProcessGroup(execCmd)
+	return execCmd, nil
+}
+
+// directCommand splits command into words using POSIX quoting rules and
+// builds a command that runs without a shell. Leading NAME=value words are
+// moved into the environment, as a shell would do.
+func directCommand(command string, env []string) (*exec.Cmd, []string, error) {
+	words, err := shellquote.Split(command)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot parse command '%s': %v", command, err)
+	}
+
+	for len(words) > 0 && isAssignment(words[0]) {
+		env = append(env, words[0])
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return nil, nil, fmt.Errorf("command '%s' only sets variables and runs nothing", command)
+	}
+
+	return exec.Command(words[0], words[1:]...), env, nil
+}
+
+// defaultShellCommand runs command through the platform shell
+func defaultShellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// shellFeature returns a description of the first shell construct found in
+// command outside of quotes, or "" when plain word splitting is enough
+func shellFeature(command string) string {
+	inSingle, inDouble, escaped := false, false, false
+	wordStart := true
+
+	for _, c := range command {
+		switch {
+		case escaped:
+			escaped = false
+			wordStart = false
+			continue
+		case inSingle:
+			if c == '\'' {
+				inSingle = false
+			}
+			continue
+		case c == '\\':
+			escaped = true
+			continue
+		case inDouble:
+			switch c {
+			case '"':
+				inDouble = false
+			case '$':
+				return "variable expansion ($)"
+			case '`':
+				return "command substitution (`)"
+			}
+			continue
+		}
+
+		switch c {
+		case '\'':
+			inSingle = true
+		case '"':
+			inDouble = true
+		case '|':
+			return "a pipe or || (|)"
+		case '&':
+			return "&& or background jobs (&)"
+		case ';':
+			return "command separators (;)"
+		case '<', '>':
+			return "redirection (< or >)"
+		case '(', ')':
+			return "subshells ( )"
+		case '$':
+			return "variable expansion ($)"
+		case '`':
+			return "command substitution (`)"
+		case '*', '?', '[':
+			return "glob patterns (* ? [)"
+		case '\n':
+			return "multiple lines"
+		case '~':
+			if wordStart {
+				return "home directory expansion (~)"
+			}
+		case '#':
+			if wordStart {
+				return "comments (#)"
+			}
+		}
+		wordStart = c == ' ' || c == '\t'
+	}
+
+	return ""
+}
+
+// isAssignment reports whether word has the form NAME=value
+func isAssignment(word string) bool {
+	key, _, ok := strings.Cut(word, "=")
+	return ok && isEnvName(key)
+}
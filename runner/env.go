@@ -0,0 +1,218 @@
+package runner
+
+import (
+	"bufio"
+	"dev-util/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultEnvFiles are loaded from the project directory, when present, for
+// projects that do not list their own env files
+var DefaultEnvFiles = []string{".env", ".env.local"}
+
+// BuildEnv assembles the environment for a project's command. Later sources
+// win: the parent environment, the project's env files, the project's Env
+// map and finally overrides (from --env). Values may reference variables with
+// ${VAR} or $VAR, which are expanded against everything defined before them.
+func BuildEnv(project *models.Project, overrides map[string]string) ([]string, error) {
+	env := newEnvMap(os.Environ())
+
+	files := project.EnvFiles
+	explicit := len(files) > 0
+	if !explicit {
+		files = DefaultEnvFiles
+	}
+
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(project.Path, path)
+		}
+
+		vars, err := LoadEnvFile(path)
+		if os.IsNotExist(err) && !explicit {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vars {
+			if v.Literal {
+				env.set(v.Key, v.Value)
+				continue
+			}
+			env.set(v.Key, env.expand(v.Value))
+		}
+	}
+
+	for _, key := range sortedKeys(project.Env) {
+		env.set(key, env.expand(project.Env[key]))
+	}
+	for _, key := range sortedKeys(overrides) {
+		env.set(key, env.expand(overrides[key]))
+	}
+
+	return env.list(), nil
+}
+
+// EnvVar is a single assignment read from an env file
+type EnvVar struct {
+	Key   string
+	Value string
+	// Literal is set for single quoted values, which must not be expanded
+	Literal bool
+}
+
+// LoadEnvFile parses a dotenv file. It understands KEY=VALUE lines, an
+// optional leading "export", # comments, and single or double quoted values.
+// Double quoted values support \n, \t, \" and \\ escapes; single quoted
+// values are taken literally. Variables are returned in file order and are
+// not expanded.
+func LoadEnvFile(path string) ([]EnvVar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var vars []EnvVar
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isEnvName(key) {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+
+		value, literal, err := parseEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNo, err)
+		}
+		vars = append(vars, EnvVar{Key: key, Value: value, Literal: literal})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return vars, nil
+}
+
+// parseEnvValue strips quotes from an env file value and removes trailing
+// comments from unquoted values. literal reports a single quoted value.
+func parseEnvValue(value string) (parsed string, literal bool, err error) {
+	if value == "" {
+		return "", false, nil
+	}
+
+	switch value[0] {
+	case '\'':
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", false, fmt.Errorf("unterminated single quote")
+		}
+		return value[1 : end+1], true, nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			c := value[i]
+			switch {
+			case c == '"':
+				return b.String(), false, nil
+			case c == '\\' && i+1 < len(value):
+				i++
+				switch value[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(value[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", false, fmt.Errorf("unterminated double quote")
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, false, nil
+}
+
+// isEnvName reports whether s is a valid environment variable name
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		isLetter := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		isDigit := c >= '0' && c <= '9'
+		if !isLetter && (i == 0 || !isDigit) {
+			return false
+		}
+	}
+	return true
+}
+
+// envMap is an environment that keeps the order in which keys were first
+// defined, so the resulting list is stable
+type envMap struct {
+	keys   []string
+	values map[string]string
+}
+
+func newEnvMap(environ []string) *envMap {
+	env := &envMap{values: map[string]string{}}
+	for _, kv := range environ {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env.set(key, value)
+		}
+	}
+	return env
+}
+
+func (e *envMap) set(key, value string) {
+	if _, exists := e.values[key]; !exists {
+		e.keys = append(e.keys, key)
+	}
+	e.values[key] = value
+}
+
+// expand substitutes ${VAR} and $VAR references with values already in the
+// environment. Unknown variables expand to the empty string.
+func (e *envMap) expand(value string) string {
+	return os.Expand(value, func(key string) string {
+		return e.values[key]
+	})
+}
+
+func (e *envMap) list() []string {
+	list := make([]string, 0, len(e.keys))
+	for _, key := range e.keys {
+		list = append(list, key+"="+e.values[key])
+	}
+	return list
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,350 @@
+package runner
+
+import (
+	"context"
+	"dev-util/logs"
+	"dev-util/watch"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Process is one command started by RunAll
+type Process struct {
+	// Name labels the process's output lines
+	Name string
+	// Cmd is the prepared command; its Stdout and Stderr are replaced
+	Cmd *exec.Cmd
+	// Command is the configured command line, recorded in the log
+	Command string
+	// Grace is how long the process gets to exit when stopped before it is
+	// killed; zero means StopTimeout
+	Grace time.Duration
+	// Log, when set, receives a copy of the output
+	Log *logs.Writer
+	// Restart, when set, decides whether the process is started again
+	// after it exits; NewCmd builds the command for each restart
+	Restart *Restarter
+	NewCmd  func() (*exec.Cmd, error)
+	// Watch, when set, restarts the process whenever its files change
+	Watch *watch.Watcher
+	// Ready, when set, is the readiness probe reported once it passes
+	Ready *Readiness
+	// DependsOn names processes of the same run that have to be ready
+	// before this one is started
+	DependsOn []string
+}
+
+// Result is the outcome of a process started by RunAll
+type Result struct {
+	Name      string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Cmd       *exec.Cmd
+	Err       error
+	// Stopped is true when the process was shut down by RunAll rather
+	// than exiting by itself
+	Stopped bool
+	// Restarts counts how often the process was restarted; the other
+	// fields describe its last run
+	Restarts int
+}
+
+// MultiOptions configures RunAll
+type MultiOptions struct {
+	// Output receives the prefixed output of every process
+	Output io.Writer
+	// Color enables colored prefixes
+	Color bool
+	// KillOthers stops every process as soon as one of them exits
+	KillOthers bool
+}
+
+// RunAll starts the processes concurrently, foreman style: each output line
+// is prefixed with the process name. When ctx is cancelled, or when one
+// process exits and KillOthers is set, the remaining processes' groups are
+// interrupted and killed if they do not exit within their grace period.
+// A process with dependencies is only started once they are ready.
+// Processes are started again according to their Restart policy and when
+// their watched files change. RunAll returns once every process has exited
+// for good.
+func RunAll(ctx context.Context, procs []Process, opts MultiOptions) []Result {
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	width := 0
+	for _, p := range procs {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+
+	ctx, stopAll := context.WithCancel(ctx)
+	defer stopAll()
+
+	var outMu sync.Mutex
+	results := make([]Result, len(procs))
+	var wg sync.WaitGroup
+
+	gates := map[string]*gate{}
+	for _, p := range procs {
+		gates[p.Name] = newGate()
+	}
+
+	for i, p := range procs {
+		prefix := FormatPrefix(p.Name, i, width, opts.Color)
+		out := NewPrefixWriter(opts.Output, prefix, &outMu)
+		results[i] = Result{Name: p.Name, Cmd: p.Cmd}
+
+		grace := p.Grace
+		if grace <= 0 {
+			grace = StopTimeout
+		}
+
+		wg.Add(1)
+		go func(p Process, res *Result, out *PrefixWriter, grace time.Duration) {
+			defer wg.Done()
+			g := gates[p.Name]
+			defer g.markFailed()
+
+			if err := waitForDependencies(ctx, p.DependsOn, gates, out); err != nil {
+				if ctx.Err() != nil {
+					res.Stopped = true
+					fmt.Fprintln(out, "stopped")
+					return
+				}
+				res.Err = err
+				fmt.Fprintf(out, "not started: %v\n", err)
+			} else {
+				supervise(ctx, p, res, out, g, grace)
+			}
+
+			if !res.Stopped && opts.KillOthers {
+				stopAll()
+			}
+		}(p, &results[i], out, grace)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// supervise runs p until it exits for good, starting it again according to
+// its restart policy and whenever its watched files change
+func supervise(ctx context.Context, p Process, res *Result, out *PrefixWriter, g *gate, grace time.Duration) {
+	var changes <-chan []string
+	if p.Watch != nil && p.NewCmd != nil {
+		changes = p.Watch.Changes()
+	}
+
+	for {
+		changed := runProcess(ctx, p, res, out, g, changes, grace)
+		if res.Stopped {
+			fmt.Fprintln(out, "stopped")
+			return
+		}
+
+		if changed == nil {
+			code := ExitCode(res.Cmd)
+			if res.StartedAt.IsZero() {
+				fmt.Fprintf(out, "failed to start: %v\n", res.Err)
+			} else {
+				fmt.Fprintf(out, "exited with code %d\n", code)
+			}
+
+			var wake <-chan time.Time
+			if p.Restart != nil && p.NewCmd != nil {
+				if delay, ok := p.Restart.Next(res.Err, res.EndedAt.Sub(res.StartedAt)); ok {
+					message := fmt.Sprintf("restarting in %s (%s)", delay, p.Restart.Describe())
+					fmt.Fprintln(out, message)
+					if p.Log != nil {
+						p.Log.Notef("exited with code %d, %s", code, message)
+					}
+					wake = time.After(delay)
+				}
+			}
+			if wake == nil {
+				if changes == nil {
+					return
+				}
+				fmt.Fprintln(out, "waiting for changes...")
+			}
+
+			select {
+			case <-wake:
+			case changed = <-changes:
+			case <-ctx.Done():
+				res.Stopped = true
+				fmt.Fprintln(out, "stopped")
+				return
+			}
+		}
+
+		if changed != nil {
+			if p.Restart != nil {
+				p.Restart.Reset()
+			}
+			message := fmt.Sprintf("%s changed, restarting", watch.Describe(changed))
+			fmt.Fprintln(out, message)
+			if p.Log != nil {
+				p.Log.Notef("%s", message)
+			}
+		}
+
+		cmd, err := p.NewCmd()
+		if err != nil {
+			res.Err = err
+			fmt.Fprintf(out, "failed to restart: %v\n", err)
+			return
+		}
+		p.Cmd = cmd
+		res.Restarts++
+	}
+}
+
+// runProcess starts p.Cmd with prefixed output and waits for it to exit or
+// for ctx to be cancelled, recording the outcome in res. When changes
+// delivers a batch of changed files first, the process is stopped and the
+// files are returned.
+func runProcess(ctx context.Context, p Process, res *Result, out *PrefixWriter, g *gate, changes <-chan []string, grace time.Duration) []string {
+	*res = Result{Name: p.Name, Cmd: p.Cmd, Restarts: res.Restarts}
+	if ctx.Err() != nil {
+		res.Stopped = true
+		return nil
+	}
+
+	errOut := out.Sibling()
+	flushLog := func() {}
+	if p.Log != nil {
+		p.Log.MarkStart(p.Command)
+		p.Cmd.Stdout, p.Cmd.Stderr, flushLog = p.Log.Tee(out, errOut)
+	} else {
+		p.Cmd.Stdout = out
+		p.Cmd.Stderr = errOut
+	}
+	if p.Ready != nil {
+		p.Ready.Reset()
+		p.Cmd.Stdout = p.Ready.Tee(p.Cmd.Stdout)
+		p.Cmd.Stderr = p.Ready.Tee(p.Cmd.Stderr)
+	}
+	setProcessGroup(p.Cmd)
+	p.Cmd.WaitDelay = grace
+
+	if err := p.Cmd.Start(); err != nil {
+		res.Err = err
+		return nil
+	}
+	startedAt := time.Now()
+	res.StartedAt = startedAt
+
+	done := make(chan error, 1)
+	go func() { done <- p.Cmd.Wait() }()
+
+	if p.Ready != nil {
+		// The probe must not outlive this attempt: res is reused for the next
+		var probe sync.WaitGroup
+		readyCtx, cancelReady := context.WithCancel(ctx)
+		defer probe.Wait()
+		defer cancelReady()
+		probe.Add(1)
+		go func() {
+			defer probe.Done()
+			err := p.Ready.Wait(readyCtx)
+			switch {
+			case err == nil:
+				g.markReady()
+				message := fmt.Sprintf("ready in %s", time.Since(startedAt).Round(100*time.Millisecond))
+				out.Println(message)
+				if p.Log != nil {
+					p.Log.Notef("%s", message)
+				}
+			case errors.Is(err, ErrNotReady):
+				g.markFailed()
+				out.Println(err.Error())
+				if p.Log != nil {
+					p.Log.Notef("%v", err)
+				}
+			}
+		}()
+	} else {
+		g.markReady()
+	}
+
+	var changed []string
+	select {
+	case res.Err = <-done:
+		Reap(p.Cmd, grace)
+	case changed = <-changes:
+		res.Err = Stop(p.Cmd, done, nil, grace)
+	case <-ctx.Done():
+		res.Stopped = true
+		res.Err = Stop(p.Cmd, done, os.Interrupt, grace)
+	}
+	res.EndedAt = time.Now()
+	flushLog()
+	out.Flush()
+	errOut.Flush()
+	return changed
+}
+
+// gate tells dependent processes whether a process became ready
+type gate struct {
+	ready     chan struct{}
+	failed    chan struct{}
+	readyOnce sync.Once
+	failOnce  sync.Once
+}
+
+func newGate() *gate {
+	return &gate{ready: make(chan struct{}), failed: make(chan struct{})}
+}
+
+func (g *gate) markReady() {
+	g.readyOnce.Do(func() { close(g.ready) })
+}
+
+// markFailed releases dependents still waiting; it has no effect on those
+// that already saw the process become ready
+func (g *gate) markFailed() {
+	g.failOnce.Do(func() { close(g.failed) })
+}
+
+// waitForDependencies blocks until every dependency that is part of the run
+// is ready. It fails when one of them exits or is not ready in time.
+func waitForDependencies(ctx context.Context, deps []string, gates map[string]*gate, out *PrefixWriter) error {
+	if len(deps) == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "waiting for %s\n", strings.Join(deps, ", "))
+
+	for _, dep := range deps {
+		g, ok := gates[dep]
+		if !ok {
+			continue
+		}
+		select {
+		case <-g.ready:
+		case <-g.failed:
+			select {
+			case <-g.ready:
+			default:
+				return fmt.Errorf("dependency '%s' is not ready", dep)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Failed reports whether a result represents a failure worth a non-zero exit
+// status. Processes stopped by RunAll do not count as failures.
+func (r Result) Failed() bool {
+	return !r.Stopped && r.Err != nil
+}
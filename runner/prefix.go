@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// prefixColors are the ANSI colors cycled through for process prefixes
+var prefixColors = []string{
+	"\033[36m", // cyan
+	"\033[33m", // yellow
+	"\033[32m", // green
+	"\033[35m", // magenta
+	"\033[34m", // blue
+	"\033[91m", // bright red
+	"\033[96m", // bright cyan
+	"\033[93m", // bright yellow
+}
+
+const colorReset = "\033[0m"
+
+// UseColor reports whether output to f should be colored: f must be a
+// terminal and NO_COLOR must not be set
+func UseColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// FormatPrefix builds the "name | " label for the index'th process, padding
+// names to width so the output columns line up
+func FormatPrefix(name string, index, width int, color bool) string {
+	label := fmt.Sprintf("%-*s | ", width, name)
+	if !color {
+		return label
+	}
+	return prefixColors[index%len(prefixColors)] + label + colorReset
+}
+
+// PrefixWriter writes every line it receives to out with a prefix in front.
+// Partial lines are buffered until their newline arrives so lines from
+// different processes sharing out never interleave mid-line.
+type PrefixWriter struct {
+	prefix string
+	out    io.Writer
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+// NewPrefixWriter returns a PrefixWriter. Writers sharing out must share mu.
+func NewPrefixWriter(out io.Writer, prefix string, mu *sync.Mutex) *PrefixWriter {
+	return &PrefixWriter{prefix: prefix, out: out, mu: mu}
+}
+
+// Write implements io.Writer
+func (w *PrefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeLine(w.buf[:i+1]); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any buffered partial line followed by a newline
+func (w *PrefixWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.writeLine(line)
+}
+
+// Println writes a complete line right away, without going through the
+// buffer, so it is safe to call while a command is writing to w
+func (w *PrefixWriter) Println(text string) error {
+	return w.writeLine([]byte(text + "\n"))
+}
+
+// Sibling returns a new PrefixWriter with the same prefix and output. A
+// command's stdout and stderr need separate writers when they are copied
+// by separate goroutines.
+func (w *PrefixWriter) Sibling() *PrefixWriter {
+	return NewPrefixWriter(w.out, w.prefix, w.mu)
+}
+
+func (w *PrefixWriter) writeLine(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := io.WriteString(w.out, w.prefix); err != nil {
+		return err
+	}
+	_, err := w.out.Write(line)
+	return err
+}
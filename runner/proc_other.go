@@ -0,0 +1,36 @@
+//go:build !unix
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Without process groups, signals reach only the direct child
+
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+var terminateSignal os.Signal = os.Interrupt
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func setForeground(cmd *exec.Cmd, tty *os.File) {}
+
+func restoreForeground(tty *os.File) {}
+
+func signalGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return cmd.Process.Signal(sig)
+}
+
+func killGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
+
+func groupAlive(cmd *exec.Cmd) bool {
+	return false
+}
+
+func exitCode(state *os.ProcessState) int {
+	return state.ExitCode()
+}
@@ -0,0 +1,73 @@
+//go:build unix
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// forwardedSignals are relayed from dev to the process group of the command
+// it runs
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
+// terminateSignal asks a process to shut down gracefully
+var terminateSignal os.Signal = syscall.SIGTERM
+
+// setProcessGroup starts cmd in a new process group, so it and every child
+// it spawns can be signalled together
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// setForeground makes cmd's process group the foreground group of tty, so
+// it can read from the terminal and receives Ctrl-C directly
+func setForeground(cmd *exec.Cmd, tty *os.File) {
+	setProcessGroup(cmd)
+	cmd.SysProcAttr.Foreground = true
+	cmd.SysProcAttr.Ctty = int(tty.Fd())
+}
+
+// restoreForeground hands tty back to dev's own process group once the
+// command has exited
+func restoreForeground(tty *os.File) {
+	// A background group changing the foreground group receives SIGTTOU
+	signal.Ignore(syscall.SIGTTOU)
+	defer signal.Reset(syscall.SIGTTOU)
+	unix.IoctlSetPointerInt(int(tty.Fd()), unix.TIOCSPGRP, syscall.Getpgrp())
+}
+
+// signalGroup sends sig to every process in cmd's process group
+func signalGroup(cmd *exec.Cmd, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return cmd.Process.Signal(sig)
+	}
+	return syscall.Kill(-cmd.Process.Pid, s)
+}
+
+// killGroup sends SIGKILL to cmd's process group
+func killGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// groupAlive reports whether any process of cmd's group is still running
+func groupAlive(cmd *exec.Cmd) bool {
+	return syscall.Kill(-cmd.Process.Pid, 0) == nil
+}
+
+// exitCode follows the shell convention of 128+N for processes killed by
+// signal N
+func exitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// StopTimeout is the default grace period processes get to exit after being
+// asked to stop before they are killed
+const StopTimeout = 10 * time.Second
+
+// reapInterval is how often a process group is checked while waiting for
+// leftover processes to exit
+const reapInterval = 50 * time.Millisecond
+
+// Run runs cmd in the foreground until it exits. The command gets its own
+// process group; SIGINT, SIGTERM and SIGHUP received by dev are forwarded to
+// the whole group, and the group is killed if it has not exited grace after
+// the first signal (or immediately on a second one). When stdin is a
+// terminal the group is made the terminal's foreground group, so Ctrl-C
+// reaches it directly. Processes left behind by the command, such as a
+// server spawned by npm, are terminated once it exits.
+func Run(cmd *exec.Cmd, grace time.Duration) error {
+	return RunContext(context.Background(), cmd, grace)
+}
+
+// RunContext is like Run, but also stops the command's process group when
+// ctx is cancelled, as it would on a signal
+func RunContext(ctx context.Context, cmd *exec.Cmd, grace time.Duration) error {
+	setProcessGroup(cmd)
+
+	var tty *os.File
+	if cmd.Stdin == os.Stdin && term.IsTerminal(int(os.Stdin.Fd())) {
+		tty = os.Stdin
+		setForeground(cmd, tty)
+	}
+
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, forwardedSignals...)
+	defer signal.Stop(sigs)
+
+	cmd.WaitDelay = grace
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	var killTimer <-chan time.Time
+	cancelled := ctx.Done()
+	for {
+		select {
+		case err := <-done:
+			if tty != nil {
+				restoreForeground(tty)
+			}
+			Reap(cmd, grace)
+			return err
+		case sig := <-sigs:
+			if killTimer != nil {
+				killGroup(cmd)
+				continue
+			}
+			signalGroup(cmd, sig)
+			killTimer = time.After(grace)
+		case <-cancelled:
+			cancelled = nil
+			if killTimer == nil {
+				signalGroup(cmd, terminateSignal)
+				killTimer = time.After(grace)
+			}
+		case <-killTimer:
+			killGroup(cmd)
+		}
+	}
+}
+
+// Stop asks cmd's process group to exit with sig and waits for it, killing
+// the group if it is still running after grace. done must deliver the result
+// of cmd.Wait.
+func Stop(cmd *exec.Cmd, done <-chan error, sig os.Signal, grace time.Duration) error {
+	if sig == nil {
+		sig = terminateSignal
+	}
+	if err := signalGroup(cmd, sig); err != nil {
+		// Some signals are not supported everywhere (e.g. Windows)
+		killGroup(cmd)
+		return <-done
+	}
+
+	select {
+	case err := <-done:
+		Reap(cmd, grace)
+		return err
+	case <-time.After(grace):
+		killGroup(cmd)
+		return <-done
+	}
+}
+
+// Reap terminates processes still left in cmd's process group after cmd
+// itself exited, killing them if they outlive grace
+func Reap(cmd *exec.Cmd, grace time.Duration) {
+	if !groupAlive(cmd) {
+		return
+	}
+
+	signalGroup(cmd, terminateSignal)
+	deadline := time.Now().Add(grace)
+	for groupAlive(cmd) {
+		if time.Now().After(deadline) {
+			killGroup(cmd)
+			return
+		}
+		time.Sleep(reapInterval)
+	}
+}
+
+// ExitCode returns the exit code of a finished command. Commands killed by a
+// signal report 128 plus the signal number, as a shell would; -1 means the
+// command never ran.
+func ExitCode(cmd *exec.Cmd) int {
+	if cmd.ProcessState == nil {
+		return -1
+	}
+	return exitCode(cmd.ProcessState)
+}
+
+// IsExitError reports whether err only says that the command exited with a
+// non-zero status, as opposed to failing to start
+func IsExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
@@ -0,0 +1,259 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"dev-util/models"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultReadyTimeout is how long a readiness check waits in total
+	DefaultReadyTimeout = time.Minute
+	// DefaultReadyInterval is how long a readiness check waits between
+	// attempts
+	DefaultReadyInterval = 500 * time.Millisecond
+
+	// minProbeTimeout bounds a single TCP or HTTP attempt from below so a
+	// short interval does not make slow servers look down
+	minProbeTimeout = time.Second
+)
+
+// ErrNotReady is returned by Readiness.Wait when the probe did not pass in
+// time
+var ErrNotReady = errors.New("not ready")
+
+// Readiness runs a project's readiness probe
+type Readiness struct {
+	project *models.Project
+	check   *models.ReadyCheck
+	env     []string
+	pattern *regexp.Regexp
+
+	// matched is closed once a log probe saw a matching line
+	mu      sync.Mutex
+	matched chan struct{}
+	closed  bool
+}
+
+// NewReadiness returns the readiness probe of a project, or nil when it
+// does not define one. env is the environment for command probes.
+func NewReadiness(project *models.Project, env []string) (*Readiness, error) {
+	check := project.Ready
+	if check == nil {
+		return nil, nil
+	}
+	if err := check.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid readiness check for '%s': %w", project.Name, err)
+	}
+
+	r := &Readiness{project: project, check: check, env: env, matched: make(chan struct{})}
+	if check.Log != "" {
+		pattern, err := regexp.Compile(check.Log)
+		if err != nil {
+			return nil, fmt.Errorf("invalid readiness log pattern for '%s': %w", project.Name, err)
+		}
+		r.pattern = pattern
+	}
+	return r, nil
+}
+
+// Describe names the probe for messages, e.g. "http http://localhost:3000"
+func (r *Readiness) Describe() string {
+	return r.check.Kind() + " " + r.check.Target()
+}
+
+// Timeout returns how long Wait waits for the probe to pass
+func (r *Readiness) Timeout() time.Duration {
+	if r.check.Timeout > 0 {
+		return r.check.Timeout.Std()
+	}
+	return DefaultReadyTimeout
+}
+
+func (r *Readiness) interval() time.Duration {
+	if r.check.Interval > 0 {
+		return r.check.Interval.Std()
+	}
+	return DefaultReadyInterval
+}
+
+// Tee returns a writer copying to w that also feeds log probes. The
+// command's stdout and stderr should each be wrapped in their own call.
+func (r *Readiness) Tee(w io.Writer) io.Writer {
+	if r.pattern == nil {
+		return w
+	}
+	return io.MultiWriter(w, &lineMatcher{readiness: r})
+}
+
+// Reset prepares the probe for a new run of the command
+func (r *Readiness) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.matched = make(chan struct{})
+	r.closed = false
+}
+
+// MatchLine feeds one line of output to a log probe
+func (r *Readiness) MatchLine(line string) {
+	if r.pattern == nil || !r.pattern.MatchString(line) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		close(r.matched)
+		r.closed = true
+	}
+}
+
+// matchedChan returns the channel closed when the current run's output
+// matched the log probe
+func (r *Readiness) matchedChan() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.matched
+}
+
+// Wait blocks until the probe passes, ctx is cancelled or the probe's
+// timeout expires. A timeout is reported as ErrNotReady together with the
+// reason of the last failed attempt.
+func (r *Readiness) Wait(ctx context.Context) error {
+	timeout := r.Timeout()
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	matched := r.matchedChan()
+	for {
+		err := r.probe(probeCtx, matched)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-matched:
+			return nil
+		case <-time.After(r.interval()):
+		case <-probeCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w after %s: %v", ErrNotReady, timeout, err)
+		}
+	}
+}
+
+// Check makes a single attempt, e.g. to find out whether a server is already
+// running. Log probes can't tell and always fail.
+func (r *Readiness) Check(ctx context.Context) error {
+	return r.probe(ctx, r.matchedChan())
+}
+
+// probe makes a single attempt
+func (r *Readiness) probe(ctx context.Context, matched <-chan struct{}) error {
+	attemptTimeout := r.interval()
+	if attemptTimeout < minProbeTimeout {
+		attemptTimeout = minProbeTimeout
+	}
+
+	switch {
+	case r.check.TCP != "":
+		dialer := net.Dialer{Timeout: attemptTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", tcpAddress(r.check.TCP))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+
+	case r.check.HTTP != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.check.HTTP, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := (&http.Client{Timeout: attemptTimeout}).Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("GET %s returned %s", r.check.HTTP, resp.Status)
+		}
+		return nil
+
+	case r.pattern != nil:
+		select {
+		case <-matched:
+			return nil
+		default:
+			return fmt.Errorf("no output line matched %q", r.check.Log)
+		}
+
+	default:
+		return r.runCommand(ctx)
+	}
+}
+
+// runCommand runs a command probe to completion, killing it when ctx ends
+func (r *Readiness) runCommand(ctx context.Context) error {
+	cmd, err := Command(r.project, models.Task{Command: r.check.Command}, r.env)
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("'%s' failed: %v", r.check.Command, err)
+		}
+		return nil
+	case <-ctx.Done():
+		killGroup(cmd)
+		<-done
+		return ctx.Err()
+	}
+}
+
+// tcpAddress completes a bare port or ":port" to a localhost address
+func tcpAddress(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	if !strings.Contains(addr, ":") {
+		return "localhost:" + addr
+	}
+	return addr
+}
+
+// lineMatcher splits output into lines for a log probe
+type lineMatcher struct {
+	readiness *Readiness
+	buf       []byte
+}
+
+func (m *lineMatcher) Write(p []byte) (int, error) {
+	m.buf = append(m.buf, p...)
+	for {
+		i := bytes.IndexByte(m.buf, '\n')
+		if i < 0 {
+			break
+		}
+		m.readiness.MatchLine(string(bytes.TrimSuffix(m.buf[:i], []byte("\r"))))
+		m.buf = m.buf[i+1:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"dev-util/models"
+	"fmt"
+	"time"
+)
+
+const (
+	// restartDelay is the wait before the first restart; it doubles with
+	// every consecutive restart up to maxRestartDelay
+	restartDelay    = time.Second
+	maxRestartDelay = 30 * time.Second
+
+	// restartResetAfter is how long a command has to stay up for its next
+	// exit to count as a fresh failure rather than another crash in a row
+	restartResetAfter = time.Minute
+)
+
+// Restarter applies a project's restart policy to a command that keeps
+// exiting, backing off exponentially between consecutive restarts
+type Restarter struct {
+	policy      string
+	maxRestarts int
+	attempts    int
+}
+
+// NewRestarter returns a Restarter for the project's restart policy and
+// max_restarts setting. Zero max_restarts means DefaultMaxRestarts and a
+// negative value means no limit.
+func NewRestarter(project *models.Project) *Restarter {
+	maxRestarts := project.MaxRestarts
+	if maxRestarts == 0 {
+		maxRestarts = models.DefaultMaxRestarts
+	}
+	return &Restarter{policy: project.Restart, maxRestarts: maxRestarts}
+}
+
+// Enabled reports whether the policy ever restarts the command
+func (r *Restarter) Enabled() bool {
+	return r.policy == models.RestartOnFailure || r.policy == models.RestartAlways
+}
+
+// Next decides whether a command that ran for uptime and exited with the
+// given Wait error should be started again, and how long to wait before
+// doing so. Commands that failed to start are never restarted.
+func (r *Restarter) Next(err error, uptime time.Duration) (time.Duration, bool) {
+	if err != nil && !IsExitError(err) {
+		return 0, false
+	}
+	switch r.policy {
+	case models.RestartAlways:
+	case models.RestartOnFailure:
+		if err == nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if uptime >= restartResetAfter {
+		r.attempts = 0
+	}
+	if r.maxRestarts > 0 && r.attempts >= r.maxRestarts {
+		return 0, false
+	}
+
+	delay := maxRestartDelay
+	if r.attempts < 16 && restartDelay<<r.attempts < delay {
+		delay = restartDelay << r.attempts
+	}
+	r.attempts++
+	return delay, true
+}
+
+// Attempts returns how many consecutive restarts have been made
+func (r *Restarter) Attempts() int {
+	return r.attempts
+}
+
+// Describe formats the current restart for messages, e.g. "restart 2/5"
+func (r *Restarter) Describe() string {
+	if r.maxRestarts < 0 {
+		return fmt.Sprintf("restart %d", r.attempts)
+	}
+	return fmt.Sprintf("restart %d/%d", r.attempts, r.maxRestarts)
+}
+
+// Reset forgets previous restarts, e.g. after the command was restarted
+// because its code changed
+func (r *Restarter) Reset() {
+	r.attempts = 0
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic writes data to a temporary file next to path, syncs it and
+// renames it over path. A crash at any point leaves either the old or the new
+// contents in place, never a truncated file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := ensureDir(path); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	// Clean up the temp file on any failure before the rename succeeds
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	success = true
+
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// syncDir flushes directory metadata so the rename itself survives a crash.
+// Errors are ignored because not every platform supports syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
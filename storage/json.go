@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"dev-util/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// JSONStore is the default Store, keeping all projects in a single JSON file
+type JSONStore struct {
+	path string
+}
+
+// NewJSONStore returns a Store backed by the JSON file at path
+func NewJSONStore(path string) *JSONStore {
+	return &JSONStore{path: path}
+}
+
+// Path returns the location of the backing file
+func (s *JSONStore) Path() string {
+	return s.path
+}
+
+// Load loads projects from the configuration file. Files written with an
+// older schema are migrated and saved back, keeping a backup of the original
+// next to it.
+func (s *JSONStore) Load() (*models.ProjectStore, error) {
+	store, migrated, err := s.read()
+	if err != nil || !migrated {
+		return store, err
+	}
+
+	// Persist the migration under the lock, re-reading in case another
+	// process migrated the file in the meantime
+	err = withFileLock(s.lockPath(), func() error {
+		store, err = s.loadLocked()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Get retrieves a project by name
+func (s *JSONStore) Get(name string) (*models.Project, error) {
+	store, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return getProject(store, name)
+}
+
+// List returns all projects
+func (s *JSONStore) List() ([]models.Project, error) {
+	store, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return store.ListProjects(), nil
+}
+
+// Add registers a new project and saves it
+func (s *JSONStore) Add(project models.Project) error {
+	return s.update(func(store *models.ProjectStore) error {
+		return addProject(store, project)
+	})
+}
+
+// Update replaces the project called name and saves it
+func (s *JSONStore) Update(name string, project models.Project) error {
+	return s.update(func(store *models.ProjectStore) error {
+		return updateProject(store, name, project)
+	})
+}
+
+// Remove deletes a project by name and saves the store
+func (s *JSONStore) Remove(name string) error {
+	return s.update(func(store *models.ProjectStore) error {
+		return removeProject(store, name)
+	})
+}
+
+// Groups returns all groups
+func (s *JSONStore) Groups() ([]models.Group, error) {
+	store, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return store.Groups, nil
+}
+
+// GetGroup retrieves a group by name
+func (s *JSONStore) GetGroup(name string) (*models.Group, error) {
+	store, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return getGroup(store, name)
+}
+
+// SaveGroup creates or replaces a group and saves the store
+func (s *JSONStore) SaveGroup(group models.Group) error {
+	return s.update(func(store *models.ProjectStore) error {
+		return saveGroup(store, group)
+	})
+}
+
+// RemoveGroup deletes a group by name and saves the store
+func (s *JSONStore) RemoveGroup(name string) error {
+	return s.update(func(store *models.ProjectStore) error {
+		return removeGroup(store, name)
+	})
+}
+
+func (s *JSONStore) lockPath() string {
+	return s.path + ".lock"
+}
+
+// update runs a load-modify-save cycle while holding the config lock, so
+// concurrent dev invocations cannot overwrite each other's changes.
+func (s *JSONStore) update(fn func(store *models.ProjectStore) error) error {
+	return withFileLock(s.lockPath(), func() error {
+		store, err := s.loadLocked()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(store); err != nil {
+			return err
+		}
+
+		return s.save(store)
+	})
+}
+
+// loadLocked loads the store and persists any migration. The caller must hold
+// the config lock.
+func (s *JSONStore) loadLocked() (*models.ProjectStore, error) {
+	store, migrated, err := s.read()
+	if err != nil || !migrated {
+		return store, err
+	}
+
+	if err := s.backup(); err != nil {
+		return nil, err
+	}
+	if err := s.save(store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// read reads and decodes the configuration file without writing anything
+// back
+func (s *JSONStore) read() (*models.ProjectStore, bool, error) {
+	// If file doesn't exist, return empty store
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return &models.ProjectStore{Version: models.CurrentVersion, Projects: []models.Project{}, Groups: []models.Group{}}, false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	store, migrated, err := decodeStore(data)
+	var newer *ErrNewerVersion
+	if errors.As(err, &newer) {
+		return nil, false, err
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return store, migrated, nil
+}
+
+// save writes the store to the configuration file. The data is written to a
+// temporary file and renamed into place, so readers never observe a
+// partially written file.
+func (s *JSONStore) save(store *models.ProjectStore) error {
+	store.Version = models.CurrentVersion
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal projects: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// backup copies the configuration file to <file>.v<version>.bak before it is
+// overwritten by a migration
+func (s *JSONStore) backup() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc map[string]interface{}
+	json.Unmarshal(data, &doc)
+	version, _ := docVersion(doc)
+
+	backupPath := fmt.Sprintf("%s.v%d.bak", s.path, version)
+	if err := writeFileAtomic(backupPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to back up config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// lockTimeout is how long a command waits for another dev process to
+	// release the config lock before giving up
+	lockTimeout = 5 * time.Second
+
+	// lockRetryInterval is the delay between attempts to take the lock
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// ErrLockTimeout is returned when the config lock could not be acquired
+// within lockTimeout
+var ErrLockTimeout = errors.New("timed out waiting for config lock")
+
+// errLocked is returned by tryLock when another process holds the lock
+var errLocked = errors.New("lock is held by another process")
+
+// withFileLock takes an exclusive advisory lock on lockPath, runs fn and
+// releases the lock again. The lock is tied to the open file descriptor, so
+// it is dropped automatically if the process dies while holding it.
+func withFileLock(lockPath string, fn func() error) error {
+	if err := ensureDir(lockPath); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer f.Close()
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := tryLock(f)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) {
+			return fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: %s is still held by another dev command after %s",
+				ErrLockTimeout, lockPath, lockTimeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	defer unlock(f)
+
+	return fn()
+}
@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package storage
+
+import "os"
+
+// Platforms without advisory locking fall back to unlocked updates; the
+// atomic rename in SaveProjects still prevents torn files.
+
+func tryLock(f *os.File) error { return nil }
+
+func unlock(f *os.File) error { return nil }
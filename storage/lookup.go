@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"dev-util/models"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindByPath returns the project whose directory contains dir, or is dir.
+// When projects are nested the innermost one wins. Symlinks are resolved on
+// both sides, so a project registered through a link is found from its real
+// location and the other way round.
+func FindByPath(store Store, dir string) (*models.Project, error) {
+	projects, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	target := resolvePath(dir)
+	var found []models.Project
+	longest := -1
+	for _, project := range projects {
+		root := resolvePath(project.Path)
+		if !within(target, root) {
+			continue
+		}
+		switch {
+		case len(root) > longest:
+			found = []models.Project{project}
+			longest = len(root)
+		case len(root) == longest:
+			found = append(found, project)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no registered project contains '%s'", dir)
+	case 1:
+		return &found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, project := range found {
+		names[i] = project.Name
+	}
+	return nil, fmt.Errorf("several projects are registered at '%s': %s", found[0].Path, strings.Join(names, ", "))
+}
+
+// resolvePath returns path made absolute with symlinks resolved, or just
+// cleaned when it cannot be resolved, e.g. because it no longer exists
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// within reports whether path is root or inside it
+func within(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"dev-util/models"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps projects in memory only. It is meant for
+// tests and throwaway sessions; nothing survives the process.
+type MemoryStore struct {
+	mu    sync.Mutex
+	store models.ProjectStore
+	runs  []models.Run
+}
+
+// NewMemoryStore returns an empty MemoryStore seeded with projects
+func NewMemoryStore(projects ...models.Project) *MemoryStore {
+	m := &MemoryStore{store: models.ProjectStore{
+		Version:  models.CurrentVersion,
+		Projects: []models.Project{},
+		Groups:   []models.Group{},
+	}}
+	for _, project := range projects {
+		addProject(&m.store, project)
+	}
+	return m
+}
+
+// snapshot returns a deep enough copy of the store that callers cannot
+// mutate the backing slice
+func (m *MemoryStore) snapshot() *models.ProjectStore {
+	projects := make([]models.Project, len(m.store.Projects))
+	copy(projects, m.store.Projects)
+
+	groups := make([]models.Group, len(m.store.Groups))
+	for i, group := range m.store.Groups {
+		group.Projects = append([]string{}, group.Projects...)
+		groups[i] = group
+	}
+	return &models.ProjectStore{Version: m.store.Version, Projects: projects, Groups: groups}
+}
+
+// Load returns a copy of the stored projects
+func (m *MemoryStore) Load() (*models.ProjectStore, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot(), nil
+}
+
+// Get retrieves a project by name
+func (m *MemoryStore) Get(name string) (*models.Project, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return getProject(&m.store, name)
+}
+
+// List returns all projects
+func (m *MemoryStore) List() ([]models.Project, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot().Projects, nil
+}
+
+// Add registers a new project
+func (m *MemoryStore) Add(project models.Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return addProject(&m.store, project)
+}
+
+// Update replaces the project called name
+func (m *MemoryStore) Update(name string, project models.Project) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return updateProject(&m.store, name, project)
+}
+
+// Remove deletes a project by name
+func (m *MemoryStore) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return removeProject(&m.store, name)
+}
+
+// Groups returns all groups
+func (m *MemoryStore) Groups() ([]models.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot().Groups, nil
+}
+
+// GetGroup retrieves a group by name
+func (m *MemoryStore) GetGroup(name string) (*models.Group, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return getGroup(m.snapshot(), name)
+}
+
+// SaveGroup creates or replaces a group
+func (m *MemoryStore) SaveGroup(group models.Group) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	group.Projects = append([]string{}, group.Projects...)
+	return saveGroup(&m.store, group)
+}
+
+// RemoveGroup deletes a group by name
+func (m *MemoryStore) RemoveGroup(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return removeGroup(&m.store, name)
+}
+
+// RecordRun appends a finished run to the history
+func (m *MemoryStore) RecordRun(run models.Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = append(m.runs, run)
+	return nil
+}
+
+// Runs returns the most recent runs, newest first
+func (m *MemoryStore) Runs(project string, limit int) ([]models.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var runs []models.Run
+	for i := len(m.runs) - 1; i >= 0; i-- {
+		if project != "" && m.runs[i].Project != project {
+			continue
+		}
+		runs = append(runs, m.runs[i])
+		if limit > 0 && len(runs) == limit {
+			break
+		}
+	}
+	return runs, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"dev-util/models"
+	"encoding/json"
+	"fmt"
+)
+
+// migration upgrades a raw store document from version N to N+1. Migrations
+// work on the decoded JSON object rather than on models.ProjectStore so they
+// can rename or reshape fields that the current structs no longer describe.
+type migration func(doc map[string]interface{}) error
+
+// migrations is indexed by the version a migration upgrades from, so
+// migrations[0] turns a version 0 document into version 1.
+var migrations = []migration{
+	migrateV0toV1,
+	migrateV1toV2,
+	migrateV2toV3,
+}
+
+// migrateV0toV1 upgrades files written before the store was versioned. The
+// layout is unchanged apart from guaranteeing a projects array.
+func migrateV0toV1(doc map[string]interface{}) error {
+	if doc["projects"] == nil {
+		doc["projects"] = []interface{}{}
+	}
+	return nil
+}
+
+// migrateV1toV2 adds project groups. The version bump keeps older builds,
+// which would drop the groups when saving, from writing the file.
+func migrateV1toV2(doc map[string]interface{}) error {
+	if doc["groups"] == nil {
+		doc["groups"] = []interface{}{}
+	}
+	return nil
+}
+
+// migrateV2toV3 adds project tags. There is nothing to convert; the version
+// bump keeps builds from before tags, which would drop them, from saving.
+func migrateV2toV3(doc map[string]interface{}) error {
+	return nil
+}
+
+// ErrNewerVersion is returned when the config file was written by a newer
+// build of dev than the one running
+type ErrNewerVersion struct {
+	Version int
+}
+
+func (e *ErrNewerVersion) Error() string {
+	return fmt.Sprintf("config file uses schema version %d but this build of dev only supports up to version %d; please upgrade dev",
+		e.Version, models.CurrentVersion)
+}
+
+// decodeStore parses data into a ProjectStore, applying any migrations that
+// are needed. The returned bool reports whether the document was migrated.
+func decodeStore(data []byte) (*models.ProjectStore, bool, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false, err
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	version, err := docVersion(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > models.CurrentVersion {
+		return nil, false, &ErrNewerVersion{Version: version}
+	}
+
+	migrated := version < models.CurrentVersion
+	for v := version; v < models.CurrentVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, false, fmt.Errorf("failed to migrate config from version %d to %d: %w", v, v+1, err)
+		}
+		doc["version"] = v + 1
+	}
+
+	if migrated {
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var store models.ProjectStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, false, err
+	}
+	if store.Projects == nil {
+		store.Projects = []models.Project{}
+	}
+	if store.Groups == nil {
+		store.Groups = []models.Group{}
+	}
+
+	return &store, migrated, nil
+}
+
+// docVersion reads the version key of a raw document. Files written before
+// versioning was introduced have no key and are treated as version 0.
+func docVersion(doc map[string]interface{}) (int, error) {
+	raw, ok := doc["version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+
+	num, ok := raw.(float64)
+	if !ok || num < 0 || num != float64(int(num)) {
+		return 0, fmt.Errorf("invalid schema version %v", raw)
+	}
+	return int(num), nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MarkRunning records that this process is running the dev server of the
+// project called name in the foreground, so other dev commands can tell. The
+// record is a pid file in the state directory that stays locked for as long
+// as the returned release function has not been called. The lock goes away
+// with the process, so a crashed dev run never looks like it is running.
+func MarkRunning(name string) (release func(), err error) {
+	path, err := runFilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureDir(path); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pid file: %w", err)
+	}
+	if err := tryLock(f); err != nil {
+		// Another dev run has it; leave its record alone
+		f.Close()
+		if errors.Is(err, errLocked) {
+			return func() {}, nil
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	f.Truncate(0)
+	f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+
+	return func() {
+		os.Remove(path)
+		unlock(f)
+		f.Close()
+	}, nil
+}
+
+// RunningPID returns the pid of the dev process running the project called
+// name in the foreground, or 0 when none is
+func RunningPID(name string) int {
+	path, err := runFilePath(name)
+	if err != nil {
+		return 0
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	if err := tryLock(f); err == nil {
+		// Nobody holds the lock, so the record is stale
+		unlock(f)
+		return 0
+	} else if !errors.Is(err, errLocked) {
+		return 0
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
+
+func runFilePath(name string) (string, error) {
+	stateDir, err := GetStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, runDir, name+".pid"), nil
+}
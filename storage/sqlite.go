@@ -0,0 +1,551 @@
+package storage
+
+import (
+	"database/sql"
+	"dev-util/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// BackendSQLite stores projects and run history in an embedded SQLite
+	// database
+	BackendSQLite = "sqlite"
+
+	sqliteFile = "projects.db"
+)
+
+// sqliteSchema creates the tables on first use. The projects table keeps the
+// commonly queried fields as columns and the complete record as JSON in data,
+// so new Project fields do not need a schema change. Run history lives in a
+// separate database in the state directory, attached as "history".
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	name        TEXT PRIMARY KEY,
+	path        TEXT NOT NULL,
+	command     TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_at  TEXT NOT NULL,
+	data        TEXT NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS project_tags (
+	project TEXT NOT NULL REFERENCES projects(name) ON DELETE CASCADE ON UPDATE CASCADE,
+	tag     TEXT NOT NULL,
+	PRIMARY KEY (project, tag)
+);
+
+CREATE TABLE IF NOT EXISTS project_groups (
+	name        TEXT PRIMARY KEY,
+	description TEXT NOT NULL DEFAULT '',
+	created_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS group_members (
+	group_name TEXT NOT NULL REFERENCES project_groups(name) ON DELETE CASCADE,
+	project    TEXT NOT NULL REFERENCES projects(name) ON DELETE CASCADE ON UPDATE CASCADE,
+	position   INTEGER NOT NULL,
+	PRIMARY KEY (group_name, project)
+);
+
+CREATE TABLE IF NOT EXISTS history.runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	project     TEXT NOT NULL,
+	started_at  TEXT NOT NULL,
+	ended_at    TEXT NOT NULL,
+	exit_code   INTEGER NOT NULL,
+	duration_ms INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS history.runs_project_started ON runs (project, started_at);
+`
+
+// HistoryStore is implemented by backends that keep a record of every
+// dev run invocation
+type HistoryStore interface {
+	// RecordRun appends a finished run to the history
+	RecordRun(run models.Run) error
+	// Runs returns the most recent runs, newest first. An empty project
+	// returns runs of every project; limit <= 0 means no limit.
+	Runs(project string, limit int) ([]models.Run, error)
+}
+
+// SQLiteStore is a Store backed by an embedded SQLite database. It uses a
+// pure-Go driver, so no cgo toolchain is needed.
+type SQLiteStore struct {
+	db   *sql.DB
+	path string
+}
+
+// OpenSQLiteStore opens (creating if necessary) the project database at path
+// and the run history database at historyPath
+func OpenSQLiteStore(path, historyPath string) (*SQLiteStore, error) {
+	if err := ensureDir(path); err != nil {
+		return nil, err
+	}
+	if err := ensureDir(historyPath); err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"busy_timeout(5000)", "foreign_keys(1)", "journal_mode(WAL)"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// ATTACH is per connection, so pin the pool to a single connection
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(`ATTACH DATABASE ? AS history`, historyPath); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialise database: %w", err)
+	}
+
+	return &SQLiteStore{db: db, path: path}, nil
+}
+
+// Path returns the location of the database file
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Load returns every project in the database
+func (s *SQLiteStore) Load() (*models.ProjectStore, error) {
+	projects, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.Groups()
+	if err != nil {
+		return nil, err
+	}
+	return &models.ProjectStore{Version: models.CurrentVersion, Projects: projects, Groups: groups}, nil
+}
+
+// Get retrieves a project by name
+func (s *SQLiteStore) Get(name string) (*models.Project, error) {
+	projects, err := s.query(`WHERE name = ?`, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("project '%s' not found", name)
+	}
+	return &projects[0], nil
+}
+
+// List returns all projects in insertion order
+func (s *SQLiteStore) List() ([]models.Project, error) {
+	return s.query(``)
+}
+
+// Add registers a new project
+func (s *SQLiteStore) Add(project models.Project) error {
+	if project.CreatedAt.IsZero() {
+		project.CreatedAt = time.Now()
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM projects WHERE name = ?`, project.Name).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			return fmt.Errorf("project '%s' already exists", project.Name)
+		}
+
+		data, err := json.Marshal(project)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO projects (name, path, command, description, created_at, data) VALUES (?, ?, ?, ?, ?, ?)`,
+			project.Name, project.Path, project.Command, project.Description, formatTime(project.CreatedAt), string(data))
+		if err != nil {
+			return err
+		}
+		return insertTags(tx, project)
+	})
+}
+
+// Update replaces the project called name
+func (s *SQLiteStore) Update(name string, project models.Project) error {
+	project.UpdatedAt = time.Now()
+
+	return s.withTx(func(tx *sql.Tx) error {
+		if project.Name != name {
+			var exists int
+			err := tx.QueryRow(`SELECT COUNT(*) FROM projects WHERE name = ?`, project.Name).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if exists > 0 {
+				return fmt.Errorf("project '%s' already exists", project.Name)
+			}
+		}
+
+		data, err := json.Marshal(project)
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(`UPDATE projects SET name = ?, path = ?, command = ?, description = ?, created_at = ?, data = ? WHERE name = ?`,
+			project.Name, project.Path, project.Command, project.Description, formatTime(project.CreatedAt), string(data), name)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("project '%s' not found", name)
+		}
+
+		// Keep the run history attached to a renamed project
+		if _, err := tx.Exec(`UPDATE history.runs SET project = ? WHERE project = ?`, project.Name, name); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM project_tags WHERE project = ?`, project.Name); err != nil {
+			return err
+		}
+		return insertTags(tx, project)
+	})
+}
+
+// Remove deletes a project by name. Its run history is kept.
+func (s *SQLiteStore) Remove(name string) error {
+	res, err := s.db.Exec(`DELETE FROM projects WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("project '%s' not found", name)
+	}
+	return nil
+}
+
+// Groups returns all groups in creation order
+func (s *SQLiteStore) Groups() ([]models.Group, error) {
+	return s.queryGroups(``)
+}
+
+// GetGroup retrieves a group by name
+func (s *SQLiteStore) GetGroup(name string) (*models.Group, error) {
+	groups, err := s.queryGroups(`WHERE name = ?`, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("group '%s' not found", name)
+	}
+	return &groups[0], nil
+}
+
+// SaveGroup creates or replaces a group
+func (s *SQLiteStore) SaveGroup(group models.Group) error {
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = time.Now()
+	}
+
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, member := range group.Projects {
+			var exists int
+			err := tx.QueryRow(`SELECT COUNT(*) FROM projects WHERE name = ?`, member).Scan(&exists)
+			if err != nil {
+				return err
+			}
+			if exists == 0 {
+				return fmt.Errorf("project '%s' not found", member)
+			}
+		}
+
+		_, err := tx.Exec(`INSERT INTO project_groups (name, description, created_at) VALUES (?, ?, ?)
+			ON CONFLICT (name) DO UPDATE SET description = excluded.description`,
+			group.Name, group.Description, formatTime(group.CreatedAt))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM group_members WHERE group_name = ?`, group.Name); err != nil {
+			return err
+		}
+		for i, member := range group.Projects {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO group_members (group_name, project, position) VALUES (?, ?, ?)`, group.Name, member, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveGroup deletes a group by name
+func (s *SQLiteStore) RemoveGroup(name string) error {
+	res, err := s.db.Exec(`DELETE FROM project_groups WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove group: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("group '%s' not found", name)
+	}
+	return nil
+}
+
+// RecordRun appends a finished run to the history
+func (s *SQLiteStore) RecordRun(run models.Run) error {
+	_, err := s.db.Exec(`INSERT INTO history.runs (project, started_at, ended_at, exit_code, duration_ms) VALUES (?, ?, ?, ?, ?)`,
+		run.Project, formatTime(run.StartedAt), formatTime(run.EndedAt), run.ExitCode, run.Duration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	return nil
+}
+
+// Runs returns the most recent runs, newest first
+func (s *SQLiteStore) Runs(project string, limit int) ([]models.Run, error) {
+	query := `SELECT project, started_at, ended_at, exit_code, duration_ms FROM history.runs`
+	var args []interface{}
+	if project != "" {
+		query += ` WHERE project = ?`
+		args = append(args, project)
+	}
+	query += ` ORDER BY started_at DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run history: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []models.Run
+	for rows.Next() {
+		var run models.Run
+		var started, ended string
+		var durationMs int64
+		if err := rows.Scan(&run.Project, &started, &ended, &run.ExitCode, &durationMs); err != nil {
+			return nil, fmt.Errorf("failed to read run history: %w", err)
+		}
+		run.StartedAt = parseTime(started)
+		run.EndedAt = parseTime(ended)
+		run.Duration = time.Duration(durationMs) * time.Millisecond
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// query loads projects matching the given WHERE clause together with their
+// tags
+func (s *SQLiteStore) query(where string, args ...interface{}) ([]models.Project, error) {
+	rows, err := s.db.Query(`SELECT name, path, command, description, created_at, data FROM projects `+where+` ORDER BY rowid`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		var project models.Project
+		var created, data string
+		var name, path, command, description string
+		if err := rows.Scan(&name, &path, &command, &description, &created, &data); err != nil {
+			return nil, fmt.Errorf("failed to read projects: %w", err)
+		}
+		if err := json.Unmarshal([]byte(data), &project); err != nil {
+			return nil, fmt.Errorf("failed to decode project '%s': %w", name, err)
+		}
+
+		// The columns are authoritative over the JSON copy
+		project.Name = name
+		project.Path = path
+		project.Command = command
+		project.Description = description
+		project.CreatedAt = parseTime(created)
+		project.Tags = nil
+		projects = append(projects, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range projects {
+		tags, err := s.tags(projects[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		projects[i].Tags = tags
+	}
+	return projects, nil
+}
+
+// queryGroups loads groups matching the given WHERE clause together with
+// their members
+func (s *SQLiteStore) queryGroups(where string, args ...interface{}) ([]models.Group, error) {
+	rows, err := s.db.Query(`SELECT name, description, created_at FROM project_groups `+where+` ORDER BY rowid`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		var group models.Group
+		var created string
+		if err := rows.Scan(&group.Name, &group.Description, &created); err != nil {
+			return nil, fmt.Errorf("failed to read groups: %w", err)
+		}
+		group.CreatedAt = parseTime(created)
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range groups {
+		members, err := s.members(groups[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		groups[i].Projects = members
+	}
+	return groups, nil
+}
+
+func (s *SQLiteStore) members(group string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT project FROM group_members WHERE group_name = ? ORDER BY position`, group)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read group members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []string{}
+	for rows.Next() {
+		var member string
+		if err := rows.Scan(&member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+func (s *SQLiteStore) tags(project string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT tag FROM project_tags WHERE project = ? ORDER BY tag`, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func insertTags(tx *sql.Tx, project models.Project) error {
+	for _, tag := range project.Tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO project_tags (project, tag) VALUES (?, ?)`, project.Name, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withTx runs fn in a transaction, committing on success
+func (s *SQLiteStore) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// ImportGroups copies groups into dst, skipping names that already exist and
+// members that are not registered in dst. It returns how many groups were
+// imported and skipped.
+func ImportGroups(dst Store, groups []models.Group) (imported, skipped int, err error) {
+	for _, group := range groups {
+		if _, err := dst.GetGroup(group.Name); err == nil {
+			skipped++
+			continue
+		}
+
+		var members []string
+		for _, member := range group.Projects {
+			if _, err := dst.Get(member); err == nil {
+				members = append(members, member)
+			}
+		}
+		group.Projects = members
+
+		if err := dst.SaveGroup(group); err != nil {
+			return imported, skipped, fmt.Errorf("failed to import group '%s': %w", group.Name, err)
+		}
+		imported++
+	}
+	return imported, skipped, nil
+}
+
+// ImportProjects copies projects into dst, skipping names that already exist.
+// It returns how many projects were imported and skipped.
+func ImportProjects(dst Store, projects []models.Project) (imported, skipped int, err error) {
+	for _, project := range projects {
+		if _, err := dst.Get(project.Name); err == nil {
+			skipped++
+			continue
+		}
+		if err := dst.Add(project); err != nil {
+			return imported, skipped, fmt.Errorf("failed to import '%s': %w", project.Name, err)
+		}
+		imported++
+	}
+	return imported, skipped, nil
+}
+
+// errNoHistory is returned when run history is requested from a backend that
+// does not record it
+var errNoHistory = errors.New("run history is only available with the sqlite backend")
+
+// History returns the HistoryStore behind store, or an error when the backend
+// does not keep run history
+func History(store Store) (HistoryStore, error) {
+	history, ok := store.(HistoryStore)
+	if !ok {
+		return nil, errNoHistory
+	}
+	return history, nil
+}
@@ -1,137 +1,215 @@
 package storage
 
 import (
-	"dev-util/models"
-	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
 )
 
 const (
-	configDir  = ".dev-util"
-	configFile = "projects.json"
+	appDir       = "dev-util"
+	legacyDir    = ".dev-util"
+	configFile   = "projects.json"
+	historyFile  = "history.db"
+	logsDir      = "logs"
+	runDir       = "run"
+	defaultPerms = 0755
+
+	// ConfigEnv points dev at an alternate projects file
+	ConfigEnv = "DEV_UTIL_CONFIG"
 )
 
-// GetConfigPath returns the path to the configuration file
+// configOverride is set from the global --config flag and takes precedence
+// over every other way of locating the projects file
+var configOverride string
+
+// SetConfigPath makes every subsequent lookup use path as the projects file.
+// An empty path restores the default resolution.
+func SetConfigPath(path string) {
+	configOverride = path
+}
+
+// GetConfigPath returns the path to the configuration file. In order of
+// precedence it is the --config flag, $DEV_UTIL_CONFIG, or projects.json in
+// $XDG_CONFIG_HOME/dev-util (~/.config/dev-util by default). Nothing is
+// created on disk; directories are made when the file is first written.
 func GetConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+	if configOverride != "" {
+		return filepath.Abs(expandHome(configOverride))
+	}
+	if env := strings.TrimSpace(os.Getenv(ConfigEnv)); env != "" {
+		return filepath.Abs(expandHome(env))
 	}
-	
-	configDirPath := filepath.Join(homeDir, configDir)
-	if err := os.MkdirAll(configDirPath, 0755); err != nil {
-		return "", fmt.Errorf("failed to create config directory: %w", err)
+
+	dir, err := GetConfigDir()
+	if err != nil {
+		return "", err
 	}
-	
-	return filepath.Join(configDirPath, configFile), nil
+	return filepath.Join(dir, configFile), nil
 }
 
-// LoadProjects loads projects from the configuration file
-func LoadProjects() (*models.ProjectStore, error) {
-	configPath, err := GetConfigPath()
+// GetConfigDir returns the default configuration directory. A legacy
+// ~/.dev-util directory is moved there the first time it is looked up.
+func GetConfigDir() (string, error) {
+	dir, err := xdgDir("XDG_CONFIG_HOME", ".config")
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	
-	// If file doesn't exist, return empty store
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return &models.ProjectStore{Projects: []models.Project{}}, nil
+
+	if err := migrateLegacyDir(dir); err != nil {
+		return "", err
 	}
-	
-	data, err := os.ReadFile(configPath)
+	return dir, nil
+}
+
+// GetStateDir returns the directory for run history, logs and other state
+// that is not configuration: $XDG_STATE_HOME/dev-util, or
+// ~/.local/state/dev-util by default.
+func GetStateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// GetLogsDir returns the directory holding captured dev server output
+func GetLogsDir() (string, error) {
+	stateDir, err := GetStateDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return "", err
 	}
-	
-	var store models.ProjectStore
-	if err := json.Unmarshal(data, &store); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
-	
-	return &store, nil
+	return filepath.Join(stateDir, logsDir), nil
 }
 
-// SaveProjects saves projects to the configuration file
-func SaveProjects(store *models.ProjectStore) error {
-	configPath, err := GetConfigPath()
+// xdgDir resolves an XDG base directory variable, falling back to fallback
+// under the home directory. Relative values are ignored as the spec requires.
+func xdgDir(envVar, fallback string) (string, error) {
+	if base := os.Getenv(envVar); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, appDir), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	
-	data, err := json.MarshalIndent(store, "", "  ")
+	return filepath.Join(homeDir, fallback, appDir), nil
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to marshal projects: %w", err)
+		return path
 	}
-	
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
+
+// ensureDir creates the parent directory of path if needed
+func ensureDir(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), defaultPerms); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
 	}
-	
 	return nil
 }
 
-// AddProject adds a new project and saves it
-func AddProject(name, path, command, description string) error {
-	store, err := LoadProjects()
+// migrateLegacyDir moves ~/.dev-util to configDir when the old directory
+// exists and the new one does not
+func migrateLegacyDir(configDir string) error {
+	homeDir, err := os.UserHomeDir()
 	if err != nil {
+		return nil
+	}
+
+	legacy := filepath.Join(homeDir, legacyDir)
+	if info, err := os.Stat(legacy); err != nil || !info.IsDir() {
+		return nil
+	}
+	if _, err := os.Stat(configDir); err == nil {
+		return nil
+	}
+
+	if err := ensureDir(configDir); err != nil {
 		return err
 	}
-	
-	// Check if project already exists
-	if _, exists := store.GetProject(name); exists {
-		return fmt.Errorf("project '%s' already exists", name)
-	}
-	
-	project := models.Project{
-		Name:        name,
-		Path:        path,
-		Command:     command,
-		Description: description,
-		CreatedAt:   time.Now(),
-	}
-	
-	store.AddProject(project)
-	return SaveProjects(store)
-}
+	if err := os.Rename(legacy, configDir); err == nil {
+		return nil
+	}
 
-// GetProject retrieves a project by name
-func GetProject(name string) (*models.Project, error) {
-	store, err := LoadProjects()
+	// Rename fails across filesystems; fall back to copying
+	skipped, err := copyTree(legacy, configDir)
 	if err != nil {
-		return nil, err
+		os.RemoveAll(configDir)
+		return fmt.Errorf("failed to migrate %s to %s: %w", legacy, configDir, err)
 	}
-	
-	project, exists := store.GetProject(name)
-	if !exists {
-		return nil, fmt.Errorf("project '%s' not found", name)
+	if len(skipped) > 0 {
+		// Keep the old directory rather than lose what could not be copied
+		fmt.Fprintf(os.Stderr, "⚠️  Copied %s to %s but kept the old directory: %s could not be copied\n",
+			legacy, configDir, strings.Join(skipped, ", "))
+		return nil
 	}
-	
-	return project, nil
+	return os.RemoveAll(legacy)
+}
+
+// copyTree copies the directory tree at src into dst, recreating symlinks
+// as links. It returns the paths, relative to src, of entries it cannot
+// copy, such as sockets and named pipes.
+func copyTree(src, dst string) ([]string, error) {
+	var skipped []string
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch mode := entry.Type(); {
+		case mode.IsDir():
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			return os.MkdirAll(target, info.Mode().Perm())
+		case mode&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case mode.IsRegular():
+			return copyFile(path, target)
+		default:
+			skipped = append(skipped, rel)
+			return nil
+		}
+	})
+	return skipped, err
 }
 
-// ListProjects returns all projects
-func ListProjects() ([]models.Project, error) {
-	store, err := LoadProjects()
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	
-	return store.ListProjects(), nil
-}
+	defer in.Close()
 
-// RemoveProject removes a project by name
-func RemoveProject(name string) error {
-	store, err := LoadProjects()
+	info, err := in.Stat()
 	if err != nil {
 		return err
 	}
-	
-	if !store.RemoveProject(name) {
-		return fmt.Errorf("project '%s' not found", name)
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
 	}
-	
-	return SaveProjects(store)
+	return out.Close()
 }
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"dev-util/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Store is the persistence backend for registered projects. Commands talk to
+// a Store rather than to a particular file format, so backends can be swapped
+// through configuration and commands can be exercised against MemoryStore.
+type Store interface {
+	// Load returns a snapshot of the whole store
+	Load() (*models.ProjectStore, error)
+	// Get retrieves a project by name
+	Get(name string) (*models.Project, error)
+	// List returns all projects
+	List() ([]models.Project, error)
+	// Add registers a new project. CreatedAt is filled in when zero.
+	Add(project models.Project) error
+	// Update replaces the project called name and stamps UpdatedAt. Renames
+	// are allowed as long as the new name is not taken.
+	Update(name string, project models.Project) error
+	// Remove deletes a project by name, also from the groups it belongs to
+	Remove(name string) error
+
+	// Groups returns all groups
+	Groups() ([]models.Group, error)
+	// GetGroup retrieves a group by name
+	GetGroup(name string) (*models.Group, error)
+	// SaveGroup creates a group or replaces the one of the same name. Every
+	// member must be a registered project. CreatedAt is filled in when zero.
+	SaveGroup(group models.Group) error
+	// RemoveGroup deletes a group by name, keeping its projects
+	RemoveGroup(name string) error
+}
+
+const (
+	// BackendEnv selects the storage backend, overriding the config file
+	BackendEnv = "DEV_UTIL_BACKEND"
+
+	settingsFile = "config.json"
+)
+
+// Backend names accepted by Open
+const (
+	BackendJSON   = "json"
+	BackendMemory = "memory"
+)
+
+// Settings holds user preferences stored in config.json next to the projects
+// file
+type Settings struct {
+	Backend string `json:"backend,omitempty"`
+}
+
+// LoadSettings reads config.json from the config directory. A missing file
+// yields the defaults.
+func LoadSettings() (*Settings, error) {
+	configPath, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &Settings{}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(configPath), settingsFile))
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	return settings, nil
+}
+
+// Open returns the configured Store. The backend is taken from the
+// DEV_UTIL_BACKEND environment variable, then from the "backend" key of
+// config.json, and defaults to the JSON file. The sqlite backend keeps its
+// database as projects.db next to projects.json and its run history in the
+// state directory.
+func Open() (Store, error) {
+	backend := strings.TrimSpace(os.Getenv(BackendEnv))
+	if backend == "" {
+		settings, err := LoadSettings()
+		if err != nil {
+			return nil, err
+		}
+		backend = settings.Backend
+	}
+
+	switch strings.ToLower(backend) {
+	case "", BackendJSON:
+		configPath, err := GetConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		return NewJSONStore(configPath), nil
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendSQLite:
+		configPath, err := GetConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		stateDir, err := GetStateDir()
+		if err != nil {
+			return nil, err
+		}
+		return OpenSQLiteStore(filepath.Join(filepath.Dir(configPath), sqliteFile),
+			filepath.Join(stateDir, historyFile))
+	default:
+		return nil, fmt.Errorf("unknown storage backend '%s' (supported: %s, %s, %s)",
+			backend, BackendJSON, BackendSQLite, BackendMemory)
+	}
+}
+
+// The helpers below implement the Store semantics on a loaded
+// models.ProjectStore so every backend reports the same errors.
+
+func getProject(store *models.ProjectStore, name string) (*models.Project, error) {
+	project, exists := store.GetProject(name)
+	if !exists {
+		return nil, fmt.Errorf("project '%s' not found", name)
+	}
+	return project, nil
+}
+
+func addProject(store *models.ProjectStore, project models.Project) error {
+	if _, exists := store.GetProject(project.Name); exists {
+		return fmt.Errorf("project '%s' already exists", project.Name)
+	}
+	if project.CreatedAt.IsZero() {
+		project.CreatedAt = time.Now()
+	}
+	store.AddProject(project)
+	return nil
+}
+
+func updateProject(store *models.ProjectStore, name string, project models.Project) error {
+	if _, exists := store.GetProject(name); !exists {
+		return fmt.Errorf("project '%s' not found", name)
+	}
+	if project.Name != name {
+		if _, exists := store.GetProject(project.Name); exists {
+			return fmt.Errorf("project '%s' already exists", project.Name)
+		}
+	}
+	project.UpdatedAt = time.Now()
+	store.UpdateProject(name, project)
+	return nil
+}
+
+func removeProject(store *models.ProjectStore, name string) error {
+	if !store.RemoveProject(name) {
+		return fmt.Errorf("project '%s' not found", name)
+	}
+	return nil
+}
+
+func getGroup(store *models.ProjectStore, name string) (*models.Group, error) {
+	group, exists := store.GetGroup(name)
+	if !exists {
+		return nil, fmt.Errorf("group '%s' not found", name)
+	}
+	return group, nil
+}
+
+func saveGroup(store *models.ProjectStore, group models.Group) error {
+	for _, member := range group.Projects {
+		if _, exists := store.GetProject(member); !exists {
+			return fmt.Errorf("project '%s' not found", member)
+		}
+	}
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = time.Now()
+	}
+	store.SaveGroup(group)
+	return nil
+}
+
+func removeGroup(store *models.ProjectStore, name string) error {
+	if !store.RemoveGroup(name) {
+		return fmt.Errorf("group '%s' not found", name)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package watch
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Match reports whether the slash-separated relative path name matches
+// pattern. "**" matches any number of directories, and a pattern without a
+// slash matches the base name at any depth, as in .gitignore files.
+func Match(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if strings.HasPrefix(pattern, "/") {
+		pattern = pattern[1:]
+	} else if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// matchAny reports whether name or one of its parent directories matches
+// one of the patterns
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		for p := name; p != "."; p = path.Dir(p) {
+			if Match(pattern, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ignoreRule is one pattern of a .gitignore file
+type ignoreRule struct {
+	// base is the directory holding the .gitignore, relative to the root
+	base    string
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// gitignore holds the rules of every .gitignore file found below the root
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// load adds the rules of dir/.gitignore, where dir is relative to root
+func (g *gitignore) load(root, dir string) {
+	g.rules = append(g.rules, readIgnoreRules(root, dir)...)
+}
+
+// readIgnoreRules parses dir/.gitignore, where dir is relative to root
+func readIgnoreRules(root, dir string) []ignoreRule {
+	f, err := os.Open(filepath.Join(root, filepath.FromSlash(dir), ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: dir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		// A slash anywhere but at the end anchors the pattern to base
+		if strings.Contains(line, "/") && !strings.HasPrefix(line, "/") && !strings.HasPrefix(line, "**/") {
+			line = "/" + line
+		}
+		rule.pattern = strings.TrimPrefix(line, "\\")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ignored reports whether the relative path name is ignored. The last
+// matching rule wins, so negated patterns can re-include files.
+func (g *gitignore) ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, rule := range g.rules {
+		rel := name
+		if rule.base != "." {
+			if !strings.HasPrefix(name, rule.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(name, rule.base+"/")
+		}
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if Match(rule.pattern, rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,151 @@
+//go:build linux
+
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const watchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_ATTRIB |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF
+
+// notifier watches every directory of the tree with inotify
+type notifier struct {
+	w    *Watcher
+	fd   int
+	file *os.File
+	// dirs maps watch descriptors to directories relative to the root
+	dirs map[int]string
+}
+
+func newNotifier(w *Watcher) (backend, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &notifier{
+		w:    w,
+		fd:   fd,
+		file: os.NewFile(uintptr(fd), "inotify"),
+		dirs: map[int]string{},
+	}
+	if err := n.addTree("."); err != nil {
+		n.file.Close()
+		return nil, err
+	}
+
+	go n.run()
+	return n, nil
+}
+
+// addTree watches dir and every wanted directory below it. Files already in
+// directories that appeared after the watch started are reported as
+// changed, since their creation events were missed.
+func (n *notifier) addTree(dir string) error {
+	var addErr error
+	err := n.w.walk(dir, func(name string, d fs.DirEntry) {
+		if !d.IsDir() {
+			if dir != "." {
+				n.w.report(name)
+			}
+			return
+		}
+		wd, err := unix.InotifyAddWatch(n.fd, filepath.Join(n.w.root, filepath.FromSlash(name)), watchMask)
+		if err != nil {
+			// Running out of watches (ENOSPC) means polling is needed
+			if addErr == nil && dir == "." {
+				addErr = err
+			}
+			return
+		}
+		n.dirs[wd] = name
+	})
+	if addErr != nil {
+		return addErr
+	}
+	return err
+}
+
+func (n *notifier) run() {
+	buf := make([]byte, 64*1024)
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= count; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+unix.SizeofInotifyEvent : offset+unix.SizeofInotifyEvent+int(event.Len)]
+			offset += unix.SizeofInotifyEvent + int(event.Len)
+
+			n.handle(event, string(trimNulls(nameBytes)))
+		}
+	}
+}
+
+func (n *notifier) handle(event *unix.InotifyEvent, name string) {
+	if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+		n.w.report(".")
+		return
+	}
+
+	dir, ok := n.dirs[int(event.Wd)]
+	if !ok {
+		return
+	}
+	if event.Mask&unix.IN_IGNORED != 0 {
+		delete(n.dirs, int(event.Wd))
+		return
+	}
+	if name == "" {
+		return
+	}
+
+	rel := path.Join(dir, name)
+	if event.Mask&unix.IN_ISDIR != 0 {
+		if event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 && n.w.wanted(rel, true) {
+			n.addTree(rel)
+		}
+		return
+	}
+	if name == ".gitignore" && n.w.opts.Gitignore {
+		n.reloadIgnore()
+	}
+	n.w.report(rel)
+}
+
+// reloadIgnore reads the .gitignore files of every watched directory again,
+// parents before children
+func (n *notifier) reloadIgnore() {
+	dirs := make([]string, 0, len(n.dirs))
+	for _, dir := range n.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	ignore := &gitignore{}
+	for _, dir := range dirs {
+		ignore.load(n.w.root, dir)
+	}
+	n.w.setIgnore(ignore)
+}
+
+func (n *notifier) close() error {
+	return n.file.Close()
+}
+
+func trimNulls(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
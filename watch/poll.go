@@ -0,0 +1,81 @@
+package watch
+
+import (
+	"io/fs"
+	"time"
+)
+
+// fileState is what the poller compares between scans
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// poller detects changes by scanning the tree every pollInterval
+type poller struct {
+	w     *Watcher
+	files map[string]fileState
+}
+
+func newPoller(w *Watcher) (backend, error) {
+	p := &poller{w: w}
+	files, err := p.scan()
+	if err != nil {
+		return nil, err
+	}
+	p.files = files
+
+	go p.run()
+	return p, nil
+}
+
+func (p *poller) run() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.w.done:
+			return
+		case <-ticker.C:
+		}
+
+		files, err := p.scan()
+		if err != nil {
+			continue
+		}
+		for name, state := range files {
+			if old, ok := p.files[name]; !ok || old != state {
+				p.w.report(name)
+			}
+		}
+		for name := range p.files {
+			if _, ok := files[name]; !ok {
+				p.w.report(name)
+			}
+		}
+		p.files = files
+	}
+}
+
+// scan records the state of every watched file. .gitignore files are read
+// again each time so edits to them take effect.
+func (p *poller) scan() (map[string]fileState, error) {
+	p.w.setIgnore(&gitignore{})
+	files := map[string]fileState{}
+	err := p.w.walk(".", func(name string, d fs.DirEntry) {
+		if d.IsDir() {
+			return
+		}
+		info, err := d.Info()
+		if err != nil {
+			return
+		}
+		files[name] = fileState{modTime: info.ModTime(), size: info.Size()}
+	})
+	return files, err
+}
+
+func (p *poller) close() error {
+	return nil
+}
@@ -0,0 +1,268 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDebounce is how long the tree has to be quiet after a change
+	// before it is reported
+	DefaultDebounce = 300 * time.Millisecond
+
+	// pollInterval is how often the tree is scanned when inotify is not
+	// available
+	pollInterval = time.Second
+)
+
+// errUnsupported is returned by newNotifier on platforms without inotify
+var errUnsupported = errors.New("file notifications are not supported on this platform")
+
+// Options selects which files are watched
+type Options struct {
+	// Include lists glob patterns of files to watch; empty means every file
+	Include []string
+	// Exclude lists glob patterns of files and directories to ignore
+	Exclude []string
+	// Gitignore skips files ignored by .gitignore files in the tree
+	Gitignore bool
+	// Debounce is how long to wait for further changes before reporting;
+	// zero means DefaultDebounce
+	Debounce time.Duration
+}
+
+// Watcher reports changes to the files below a directory. Changes arriving
+// in quick succession are reported together once things have settled.
+type Watcher struct {
+	root string
+	opts Options
+	// ignore is replaced and extended by the backend while other goroutines
+	// read it, so it is only used through the methods holding ignoreMu
+	ignore   *gitignore
+	ignoreMu sync.RWMutex
+	backend  backend
+	mode     string
+
+	events  chan string
+	changes chan []string
+	done    chan struct{}
+}
+
+// backend delivers the relative paths of changed files to Watcher.events
+type backend interface {
+	close() error
+}
+
+// New starts watching root. It uses inotify where available and falls back
+// to periodically scanning the tree.
+func New(root string, opts Options) (*Watcher, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+
+	w := &Watcher{
+		root:    root,
+		opts:    opts,
+		ignore:  &gitignore{},
+		events:  make(chan string, 256),
+		changes: make(chan []string),
+		done:    make(chan struct{}),
+	}
+
+	w.mode = "inotify"
+	w.backend, err = newNotifier(w)
+	if err != nil {
+		w.mode = "polling"
+		if w.backend, err = newPoller(w); err != nil {
+			return nil, err
+		}
+	}
+
+	go w.debounce()
+	return w, nil
+}
+
+// Changes delivers the sorted relative paths of the files changed since the
+// previous delivery
+func (w *Watcher) Changes() <-chan []string {
+	return w.changes
+}
+
+// Mode names the mechanism used to detect changes, "inotify" or "polling"
+func (w *Watcher) Mode() string {
+	return w.mode
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+	}
+	close(w.done)
+	return w.backend.close()
+}
+
+// debounce collects changed paths until none arrived for the debounce
+// interval and then delivers them
+func (w *Watcher) debounce() {
+	pending := map[string]bool{}
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case name := <-w.events:
+			pending[name] = true
+			timer = time.After(w.opts.Debounce)
+		case <-timer:
+			timer = nil
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			pending = map[string]bool{}
+
+			select {
+			case w.changes <- names:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// report queues a changed path for delivery when it is relevant
+func (w *Watcher) report(name string) {
+	if !w.wanted(name, false) {
+		return
+	}
+	select {
+	case w.events <- name:
+	case <-w.done:
+	}
+}
+
+// wanted reports whether the relative path name should be watched: it is
+// not excluded or ignored and, for files, matches the include patterns
+func (w *Watcher) wanted(name string, isDir bool) bool {
+	if name == "." {
+		return true
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".git" {
+			return false
+		}
+	}
+	if matchAny(w.opts.Exclude, name) {
+		return false
+	}
+
+	if w.opts.Gitignore && w.gitignored(name, isDir) {
+		return false
+	}
+
+	if isDir || len(w.opts.Include) == 0 {
+		return true
+	}
+	for _, pattern := range w.opts.Include {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitignored reports whether name or one of its parent directories is
+// ignored by the .gitignore rules loaded so far
+func (w *Watcher) gitignored(name string, isDir bool) bool {
+	w.ignoreMu.RLock()
+	defer w.ignoreMu.RUnlock()
+
+	if w.ignore.ignored(name, isDir) {
+		return true
+	}
+	for dir := filepath.ToSlash(filepath.Dir(name)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if w.ignore.ignored(dir, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIgnore adds the rules of dir/.gitignore to the current rules
+func (w *Watcher) loadIgnore(dir string) {
+	rules := readIgnoreRules(w.root, dir)
+	if len(rules) == 0 {
+		return
+	}
+	w.ignoreMu.Lock()
+	w.ignore.rules = append(w.ignore.rules, rules...)
+	w.ignoreMu.Unlock()
+}
+
+// setIgnore replaces the .gitignore rules
+func (w *Watcher) setIgnore(ignore *gitignore) {
+	w.ignoreMu.Lock()
+	w.ignore = ignore
+	w.ignoreMu.Unlock()
+}
+
+// walk calls fn for every wanted directory and file below dir (relative to
+// the root), loading .gitignore files on the way down
+func (w *Watcher) walk(dir string, fn func(name string, d fs.DirEntry)) error {
+	return filepath.WalkDir(filepath.Join(w.root, filepath.FromSlash(dir)), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files disappearing during the walk are expected
+			if d != nil && d.IsDir() && path != w.root {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if !w.wanted(rel, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && w.opts.Gitignore {
+			w.loadIgnore(rel)
+		}
+		fn(rel, d)
+		return nil
+	})
+}
+
+// Describe summarizes changed paths for messages, e.g. "main.go" or
+// "main.go and 2 more files"
+func Describe(names []string) string {
+	switch len(names) {
+	case 0:
+		return "Files"
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and 1 more file"
+	}
+	return fmt.Sprintf("%s and %d more files", names[0], len(names)-1)
+}